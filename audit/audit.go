@@ -0,0 +1,250 @@
+package audit
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+)
+
+type Entry struct {
+	Tenant     domain.TenantID      `json:"tenant"`
+	Actor      string               `json:"actor"`
+	Role       usecase.Role         `json:"role"`
+	Action     string               `json:"action"`
+	Input      map[string]string    `json:"input,omitempty"`
+	ShipmentID domain.ShipmentID    `json:"shipment_id"`
+	State      domain.ShipmentState `json:"state"`
+	Error      string               `json:"error,omitempty"`
+	At         time.Time            `json:"at"`
+}
+
+type Sink interface {
+	Append(Entry) error
+	Query(Query) ([]Entry, error)
+}
+
+type Query struct {
+	Tenant     domain.TenantID
+	Actor      string
+	Action     string
+	ShipmentID domain.ShipmentID
+	From       time.Time
+	To         time.Time
+	FailedOnly bool
+}
+
+func (q Query) Matches(e Entry) bool {
+	return (q.Tenant == "" || q.Tenant == e.Tenant) &&
+		(q.Actor == "" || q.Actor == e.Actor) &&
+		(q.Action == "" || q.Action == e.Action) &&
+		(q.ShipmentID == 0 || q.ShipmentID == e.ShipmentID) &&
+		(q.From.IsZero() || !e.At.Before(q.From)) &&
+		(q.To.IsZero() || e.At.Before(q.To)) &&
+		(!q.FailedOnly || e.Error != "")
+}
+
+type ShipmentUseCase interface {
+	Get(usecase.Actor, domain.ShipmentID) (domain.Shipment, error)
+	Create(actor usecase.Actor, origin string, destination string, opts ...usecase.CreateOption) (domain.Shipment, error)
+	Handle(usecase.Actor, domain.ShipmentID) (domain.Shipment, error)
+	Ship(usecase.Actor, domain.ShipmentID) (domain.Shipment, error)
+	Deliver(usecase.Actor, domain.ShipmentID, domain.ProofOfDelivery) (domain.Shipment, error)
+	ProofOfDelivery(usecase.Actor, domain.ShipmentID) (domain.ProofOfDelivery, error)
+	Cancel(usecase.Actor, domain.ShipmentID, domain.CancellationReason) (domain.Shipment, error)
+	InitiateReturn(usecase.Actor, domain.ShipmentID) (domain.Shipment, error)
+	ReportDeliveryAttempt(usecase.Actor, domain.ShipmentID, domain.FailureReason) (domain.Shipment, error)
+	DeclareCustoms(usecase.Actor, domain.ShipmentID, domain.CustomsDeclaration) (domain.Shipment, error)
+	PlanLeg(usecase.Actor, domain.ShipmentID, domain.Leg) (domain.Shipment, error)
+	DepartLeg(actor usecase.Actor, id domain.ShipmentID, leg int) (domain.Shipment, error)
+	ArriveLeg(actor usecase.Actor, id domain.ShipmentID, leg int) (domain.Shipment, error)
+}
+
+type HoldUseCase interface {
+	PlaceHold(actor usecase.Actor, id domain.ShipmentID, reason string) (domain.Shipment, error)
+	Release(usecase.Actor, domain.ShipmentID) (domain.Shipment, error)
+	OnHold(usecase.Actor) ([]domain.Shipment, error)
+}
+
+type recorder struct {
+	tenant domain.TenantID
+	sink   Sink
+	now    func() time.Time
+	failed func(Entry, error)
+}
+
+type auditedShipmentUseCase struct {
+	recorder
+	next ShipmentUseCase
+}
+
+type auditedHoldUseCase struct {
+	recorder
+	next HoldUseCase
+}
+
+// NewShipmentUseCase records every call to next, successful or not, in the
+// sink under tenant, which next must be scoped to. The entry is written once
+// next has returned, so an entry that can not be recorded never changes the
+// outcome of the call: next's result is returned as is and the failure is
+// logged, or handed to the handler set with WithFailureHandler.
+func NewShipmentUseCase(tenant domain.TenantID, next ShipmentUseCase, sink Sink, now func() time.Time) auditedShipmentUseCase {
+	return auditedShipmentUseCase{recorder{tenant, sink, now, logFailure}, next}
+}
+
+// NewHoldUseCase records every hold placed or released through next under
+// tenant.
+func NewHoldUseCase(tenant domain.TenantID, next HoldUseCase, sink Sink, now func() time.Time) auditedHoldUseCase {
+	return auditedHoldUseCase{recorder{tenant, sink, now, logFailure}, next}
+}
+
+func (uc auditedShipmentUseCase) WithFailureHandler(failed func(Entry, error)) auditedShipmentUseCase {
+	uc.failed = failed
+	return uc
+}
+
+func (uc auditedHoldUseCase) WithFailureHandler(failed func(Entry, error)) auditedHoldUseCase {
+	uc.failed = failed
+	return uc
+}
+
+func (uc auditedShipmentUseCase) Get(actor usecase.Actor, id domain.ShipmentID) (domain.Shipment, error) {
+	s, err := uc.next.Get(actor, id)
+	return uc.record(actor, "Get", nil, id, s, err)
+}
+
+func (uc auditedShipmentUseCase) Create(actor usecase.Actor, origin string, destination string, opts ...usecase.CreateOption) (domain.Shipment, error) {
+	s, err := uc.next.Create(actor, origin, destination, opts...)
+	return uc.record(actor, "Create", createInput(origin, destination, opts), 0, s, err)
+}
+
+// createInput records the options the way Create applies them, so the entry
+// holds what was asked for even when Create fails.
+func createInput(origin string, destination string, opts []usecase.CreateOption) map[string]string {
+	var requested domain.Shipment
+	for _, opt := range opts {
+		opt(&requested)
+	}
+
+	input := map[string]string{"origin": origin, "destination": destination}
+	if requested.ServiceLevel != "" {
+		input["service_level"] = string(requested.ServiceLevel)
+	}
+	if requested.OriginCountry != "" || requested.DestinationCountry != "" {
+		input["origin_country"] = requested.OriginCountry
+		input["destination_country"] = requested.DestinationCountry
+	}
+	if len(requested.Parcels) > 0 {
+		weights := make([]string, 0, len(requested.Parcels))
+		for _, p := range requested.Parcels {
+			weights = append(weights, strconv.Itoa(p.Weight))
+		}
+		input["parcels"] = strings.Join(weights, ",")
+	}
+
+	return input
+}
+
+func (uc auditedShipmentUseCase) Handle(actor usecase.Actor, id domain.ShipmentID) (domain.Shipment, error) {
+	s, err := uc.next.Handle(actor, id)
+	return uc.record(actor, "Handle", nil, id, s, err)
+}
+
+func (uc auditedShipmentUseCase) Ship(actor usecase.Actor, id domain.ShipmentID) (domain.Shipment, error) {
+	s, err := uc.next.Ship(actor, id)
+	return uc.record(actor, "Ship", nil, id, s, err)
+}
+
+func (uc auditedShipmentUseCase) Deliver(actor usecase.Actor, id domain.ShipmentID, proof domain.ProofOfDelivery) (domain.Shipment, error) {
+	s, err := uc.next.Deliver(actor, id, proof)
+	return uc.record(actor, "Deliver", map[string]string{"recipient": proof.RecipientName}, id, s, err)
+}
+
+func (uc auditedShipmentUseCase) ProofOfDelivery(actor usecase.Actor, id domain.ShipmentID) (domain.ProofOfDelivery, error) {
+	p, err := uc.next.ProofOfDelivery(actor, id)
+	uc.record(actor, "ProofOfDelivery", nil, id, domain.Shipment{}, err)
+	return p, err
+}
+
+func (uc auditedShipmentUseCase) Cancel(actor usecase.Actor, id domain.ShipmentID, reason domain.CancellationReason) (domain.Shipment, error) {
+	s, err := uc.next.Cancel(actor, id, reason)
+	return uc.record(actor, "Cancel", map[string]string{"reason": string(reason)}, id, s, err)
+}
+
+func (uc auditedShipmentUseCase) InitiateReturn(actor usecase.Actor, id domain.ShipmentID) (domain.Shipment, error) {
+	s, err := uc.next.InitiateReturn(actor, id)
+	return uc.record(actor, "InitiateReturn", nil, id, s, err)
+}
+
+func (uc auditedShipmentUseCase) ReportDeliveryAttempt(actor usecase.Actor, id domain.ShipmentID, reason domain.FailureReason) (domain.Shipment, error) {
+	s, err := uc.next.ReportDeliveryAttempt(actor, id, reason)
+	return uc.record(actor, "ReportDeliveryAttempt", map[string]string{"reason": string(reason)}, id, s, err)
+}
+
+func (uc auditedShipmentUseCase) DeclareCustoms(actor usecase.Actor, id domain.ShipmentID, d domain.CustomsDeclaration) (domain.Shipment, error) {
+	s, err := uc.next.DeclareCustoms(actor, id, d)
+	return uc.record(actor, "DeclareCustoms", map[string]string{"incoterm": string(d.Incoterm), "currency": d.Currency}, id, s, err)
+}
+
+func (uc auditedShipmentUseCase) PlanLeg(actor usecase.Actor, id domain.ShipmentID, l domain.Leg) (domain.Shipment, error) {
+	s, err := uc.next.PlanLeg(actor, id, l)
+	return uc.record(actor, "PlanLeg", map[string]string{"from": l.From, "to": l.To, "carrier": l.Carrier, "mode": string(l.Mode)}, id, s, err)
+}
+
+func (uc auditedShipmentUseCase) DepartLeg(actor usecase.Actor, id domain.ShipmentID, leg int) (domain.Shipment, error) {
+	s, err := uc.next.DepartLeg(actor, id, leg)
+	return uc.record(actor, "DepartLeg", map[string]string{"leg": strconv.Itoa(leg)}, id, s, err)
+}
+
+func (uc auditedShipmentUseCase) ArriveLeg(actor usecase.Actor, id domain.ShipmentID, leg int) (domain.Shipment, error) {
+	s, err := uc.next.ArriveLeg(actor, id, leg)
+	return uc.record(actor, "ArriveLeg", map[string]string{"leg": strconv.Itoa(leg)}, id, s, err)
+}
+
+func (uc auditedHoldUseCase) PlaceHold(actor usecase.Actor, id domain.ShipmentID, reason string) (domain.Shipment, error) {
+	s, err := uc.next.PlaceHold(actor, id, reason)
+	return uc.record(actor, "PlaceHold", map[string]string{"reason": reason}, id, s, err)
+}
+
+func (uc auditedHoldUseCase) Release(actor usecase.Actor, id domain.ShipmentID) (domain.Shipment, error) {
+	s, err := uc.next.Release(actor, id)
+	return uc.record(actor, "Release", nil, id, s, err)
+}
+
+func (uc auditedHoldUseCase) OnHold(actor usecase.Actor) ([]domain.Shipment, error) {
+	held, err := uc.next.OnHold(actor)
+	uc.record(actor, "OnHold", nil, 0, domain.Shipment{}, err)
+	return held, err
+}
+
+func (r recorder) record(actor usecase.Actor, action string, input map[string]string, id domain.ShipmentID, s domain.Shipment, err error) (domain.Shipment, error) {
+	e := Entry{
+		Tenant:     r.tenant,
+		Actor:      actor.ID,
+		Role:       actor.Role,
+		Action:     action,
+		Input:      input,
+		ShipmentID: id,
+		State:      s.State,
+		At:         r.now(),
+	}
+	if e.ShipmentID == 0 {
+		e.ShipmentID = s.ID
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	if sinkErr := r.sink.Append(e); sinkErr != nil {
+		r.failed(e, sinkErr)
+	}
+
+	return s, err
+}
+
+func logFailure(e Entry, err error) {
+	log.Printf("could not record audit entry %s of shipment %d for %s: %s", e.Action, e.ShipmentID, e.Actor, err)
+}
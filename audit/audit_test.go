@@ -0,0 +1,277 @@
+package audit_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/audit"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+var auditAt = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+
+var _ audit.ShipmentUseCase = usecase.NewShipmentUseCase(nil, nil, nil)
+var _ audit.HoldUseCase = usecase.NewHoldUseCase(nil, nil, nil, nil)
+
+type useCaseStub struct {
+	shipment domain.Shipment
+	err      error
+}
+
+func (uc useCaseStub) Get(usecase.Actor, domain.ShipmentID) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) Create(usecase.Actor, string, string, ...usecase.CreateOption) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) Handle(usecase.Actor, domain.ShipmentID) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) Ship(usecase.Actor, domain.ShipmentID) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) Deliver(usecase.Actor, domain.ShipmentID, domain.ProofOfDelivery) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) ProofOfDelivery(usecase.Actor, domain.ShipmentID) (domain.ProofOfDelivery, error) {
+	return domain.ProofOfDelivery{}, uc.err
+}
+
+func (uc useCaseStub) Cancel(usecase.Actor, domain.ShipmentID, domain.CancellationReason) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) InitiateReturn(usecase.Actor, domain.ShipmentID) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) ReportDeliveryAttempt(usecase.Actor, domain.ShipmentID, domain.FailureReason) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) DeclareCustoms(usecase.Actor, domain.ShipmentID, domain.CustomsDeclaration) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) PlanLeg(usecase.Actor, domain.ShipmentID, domain.Leg) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) DepartLeg(usecase.Actor, domain.ShipmentID, int) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) ArriveLeg(usecase.Actor, domain.ShipmentID, int) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) PlaceHold(usecase.Actor, domain.ShipmentID, string) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) Release(usecase.Actor, domain.ShipmentID) (domain.Shipment, error) {
+	return uc.shipment, uc.err
+}
+
+func (uc useCaseStub) OnHold(usecase.Actor) ([]domain.Shipment, error) {
+	return []domain.Shipment{uc.shipment}, uc.err
+}
+
+type failingSink struct{}
+
+func (failingSink) Append(audit.Entry) error {
+	return errors.New("Disk full")
+}
+
+func (failingSink) Query(audit.Query) ([]audit.Entry, error) {
+	return nil, nil
+}
+
+func clock() time.Time {
+	return auditAt
+}
+
+func TestAuditedShipmentUseCase_Create_OK(t *testing.T) {
+	next := useCaseStub{shipment: domain.Shipment{ID: 7, State: domain.Created}}
+	sink := audit.NewMemorySink()
+	uc := audit.NewShipmentUseCase("acme", next, sink, clock)
+
+	s, err := uc.Create(usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}, "valid origin", "valid destination")
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.ShipmentID(7), s.ID)
+	entries, _ := sink.Query(audit.Query{})
+	assert.Equal(t, []audit.Entry{{
+		Tenant:     "acme",
+		Actor:      "merchant-1",
+		Role:       usecase.Merchant,
+		Action:     "Create",
+		Input:      map[string]string{"origin": "valid origin", "destination": "valid destination"},
+		ShipmentID: 7,
+		State:      domain.Created,
+		At:         auditAt,
+	}}, entries)
+}
+
+func TestAuditedShipmentUseCase_Deliver_Error(t *testing.T) {
+	next := useCaseStub{shipment: domain.Shipment{ID: 7, State: domain.Created}, err: usecase.ShipmentCanNotBeDelivered}
+	sink := audit.NewMemorySink()
+	uc := audit.NewShipmentUseCase("acme", next, sink, clock)
+
+	_, err := uc.Deliver(usecase.Actor{ID: "driver-1", Role: usecase.Driver}, 7, domain.ProofOfDelivery{RecipientName: "Jane Doe"})
+
+	assert.Equal(t, usecase.ShipmentCanNotBeDelivered, err)
+	entries, _ := sink.Query(audit.Query{FailedOnly: true})
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "Deliver", entries[0].Action)
+		assert.Equal(t, "Jane Doe", entries[0].Input["recipient"])
+		assert.Equal(t, usecase.ShipmentCanNotBeDelivered.Error(), entries[0].Error)
+		assert.Equal(t, domain.Created, entries[0].State)
+	}
+}
+
+func TestAuditedShipmentUseCase_Create_Options(t *testing.T) {
+	next := useCaseStub{err: usecase.CouldNotCreateShipment}
+	sink := audit.NewMemorySink()
+	uc := audit.NewShipmentUseCase("acme", next, sink, clock)
+
+	uc.Create(usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}, "valid origin", "valid destination",
+		usecase.WithServiceLevel(domain.Express),
+		usecase.WithCountries("AR", "ES"),
+		usecase.WithParcels(domain.Parcel{Weight: 1500}, domain.Parcel{Weight: 200}),
+	)
+
+	entries, _ := sink.Query(audit.Query{})
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, map[string]string{
+			"origin":              "valid origin",
+			"destination":         "valid destination",
+			"service_level":       "Express",
+			"origin_country":      "AR",
+			"destination_country": "ES",
+			"parcels":             "1500,200",
+		}, entries[0].Input)
+	}
+}
+
+func TestAuditedShipmentUseCase_SinkFailure(t *testing.T) {
+	cases := []struct {
+		name     string
+		next     useCaseStub
+		expected error
+	}{
+		{name: "Committed", next: useCaseStub{shipment: domain.Shipment{ID: 7, State: domain.Cancelled}}},
+		{name: "Forbidden", next: useCaseStub{shipment: domain.Shipment{ID: 7, State: domain.Created}, err: usecase.Forbidden}, expected: usecase.Forbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var failed []audit.Entry
+			uc := audit.NewShipmentUseCase("acme", c.next, failingSink{}, clock).
+				WithFailureHandler(func(e audit.Entry, err error) {
+					failed = append(failed, e)
+				})
+
+			s, err := uc.Cancel(usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}, 7, domain.CustomerRequest)
+
+			assert.Equal(t, c.expected, err)
+			assert.Equal(t, c.next.shipment, s)
+			if assert.Len(t, failed, 1) {
+				assert.Equal(t, "Cancel", failed[0].Action)
+			}
+		})
+	}
+}
+
+func TestAuditedShipmentUseCase_RecordsEveryAction(t *testing.T) {
+	next := useCaseStub{shipment: domain.Shipment{ID: 7, State: domain.Shipped}}
+	sink := audit.NewMemorySink()
+	uc := audit.NewShipmentUseCase("acme", next, sink, clock)
+
+	uc.Get(usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}, 7)
+	uc.Create(usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}, "a", "b")
+	uc.Handle(usecase.Actor{ID: "warehouse-1", Role: usecase.Warehouse}, 7)
+	uc.Ship(usecase.Actor{ID: "warehouse-1", Role: usecase.Warehouse}, 7)
+	uc.Deliver(usecase.Actor{ID: "driver-1", Role: usecase.Driver}, 7, domain.ProofOfDelivery{})
+	uc.ProofOfDelivery(usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}, 7)
+	uc.Cancel(usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}, 7, domain.CustomerRequest)
+	uc.InitiateReturn(usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}, 7)
+	uc.ReportDeliveryAttempt(usecase.Actor{ID: "driver-1", Role: usecase.Driver}, 7, domain.RecipientAbsent)
+	uc.DeclareCustoms(usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}, 7, domain.CustomsDeclaration{})
+	uc.PlanLeg(usecase.Actor{ID: "warehouse-1", Role: usecase.Warehouse}, 7, domain.Leg{})
+	uc.DepartLeg(usecase.Actor{ID: "warehouse-1", Role: usecase.Warehouse}, 7, 0)
+	uc.ArriveLeg(usecase.Actor{ID: "warehouse-1", Role: usecase.Warehouse}, 7, 0)
+
+	holds := audit.NewHoldUseCase("acme", next, sink, clock)
+	holds.PlaceHold(usecase.Actor{ID: "warehouse-1", Role: usecase.Warehouse}, 7, "address check")
+	holds.Release(usecase.Actor{ID: "warehouse-1", Role: usecase.Warehouse}, 7)
+	holds.OnHold(usecase.Actor{ID: "warehouse-1", Role: usecase.Warehouse})
+
+	entries, _ := sink.Query(audit.Query{Tenant: "acme"})
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	assert.Equal(t, []string{
+		"Get", "Create", "Handle", "Ship", "Deliver", "ProofOfDelivery", "Cancel", "InitiateReturn",
+		"ReportDeliveryAttempt", "DeclareCustoms", "PlanLeg", "DepartLeg", "ArriveLeg",
+		"PlaceHold", "Release", "OnHold",
+	}, actions)
+}
+
+func TestAuditedHoldUseCase_PlaceHold_Error(t *testing.T) {
+	next := useCaseStub{shipment: domain.Shipment{ID: 7, State: domain.Created}, err: usecase.Forbidden}
+	sink := audit.NewMemorySink()
+	uc := audit.NewHoldUseCase("acme", next, sink, clock)
+
+	_, err := uc.PlaceHold(usecase.Actor{ID: "driver-1", Role: usecase.Driver}, 7, "address check")
+
+	assert.Equal(t, usecase.Forbidden, err)
+	entries, _ := sink.Query(audit.Query{FailedOnly: true})
+	assert.Equal(t, []audit.Entry{{
+		Tenant:     "acme",
+		Actor:      "driver-1",
+		Role:       usecase.Driver,
+		Action:     "PlaceHold",
+		Input:      map[string]string{"reason": "address check"},
+		ShipmentID: 7,
+		State:      domain.Created,
+		Error:      usecase.Forbidden.Error(),
+		At:         auditAt,
+	}}, entries)
+}
+
+func TestQuery_Matches(t *testing.T) {
+	e := audit.Entry{Tenant: "acme", Actor: "driver-1", Action: "Deliver", ShipmentID: 7, At: auditAt}
+
+	cases := []struct {
+		name     string
+		query    audit.Query
+		expected bool
+	}{
+		{name: "Empty", query: audit.Query{}, expected: true},
+		{name: "Tenant", query: audit.Query{Tenant: "acme"}, expected: true},
+		{name: "Other Tenant", query: audit.Query{Tenant: "globex"}, expected: false},
+		{name: "Actor", query: audit.Query{Actor: "driver-1"}, expected: true},
+		{name: "Other Actor", query: audit.Query{Actor: "driver-2"}, expected: false},
+		{name: "Other Action", query: audit.Query{Action: "Cancel"}, expected: false},
+		{name: "Other Shipment", query: audit.Query{ShipmentID: 8}, expected: false},
+		{name: "From", query: audit.Query{From: auditAt}, expected: true},
+		{name: "To", query: audit.Query{To: auditAt}, expected: false},
+		{name: "Failed Only", query: audit.Query{FailedOnly: true}, expected: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.query.Matches(e))
+		})
+	}
+}
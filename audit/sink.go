@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+type memorySink struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func NewMemorySink() *memorySink {
+	return &memorySink{}
+}
+
+func (s *memorySink) Append(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, e)
+
+	return nil
+}
+
+func (s *memorySink) Query(q Query) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found []Entry
+	for _, e := range s.entries {
+		if q.Matches(e) {
+			found = append(found, e)
+		}
+	}
+
+	return found, nil
+}
+
+// fileSink stores one JSON entry per line in a file that is only ever
+// opened for appending.
+type fileSink struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+func NewFileSink(path string) (*fileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileSink{path: path, file: f}, nil
+}
+
+func (s *fileSink) Append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return s.file.Sync()
+}
+
+func (s *fileSink) Query(q Query) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var found []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		if q.Matches(e) {
+			found = append(found, e)
+		}
+	}
+
+	return found, scanner.Err()
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
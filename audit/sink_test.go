@@ -0,0 +1,55 @@
+package audit_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/audit"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSink_OK(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	sink, err := audit.NewFileSink(path)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	sink.Append(audit.Entry{Actor: "merchant-1", Action: "Create", ShipmentID: 1, State: domain.Created, At: auditAt})
+	sink.Append(audit.Entry{Actor: "driver-1", Action: "Deliver", ShipmentID: 1, Error: "Shipment is not shipped", At: auditAt})
+	sink.Close()
+
+	reopened, err := audit.NewFileSink(path)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	defer reopened.Close()
+	reopened.Append(audit.Entry{Actor: "merchant-1", Action: "Cancel", ShipmentID: 2, At: auditAt})
+
+	all, err := reopened.Query(audit.Query{})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Len(t, all, 3)
+
+	byMerchant, _ := reopened.Query(audit.Query{Actor: "merchant-1"})
+	assert.Len(t, byMerchant, 2)
+
+	failed, _ := reopened.Query(audit.Query{FailedOnly: true})
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, "driver-1", failed[0].Actor)
+		assert.True(t, failed[0].At.Equal(auditAt))
+	}
+}
+
+func TestNewFileSink_Error(t *testing.T) {
+	_, err := audit.NewFileSink(filepath.Join("missing", "dir", "audit.log"))
+
+	assert.Error(t, err)
+}
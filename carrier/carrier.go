@@ -0,0 +1,115 @@
+package carrier
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+var UnknownCarrier = errors.New("Unknown carrier")
+var CarrierAlreadyRegistered = errors.New("Carrier already registered")
+var NoCarrierForRoute = errors.New("No carrier for route")
+var UnknownTrackingNumber = errors.New("Unknown tracking number")
+
+type Booking struct {
+	TrackingNumber string
+	PickupAt       time.Time
+}
+
+type Label struct {
+	Format string
+	Data   []byte
+}
+
+type TrackingEvent struct {
+	Status   string
+	Location string
+	At       time.Time
+}
+
+type Carrier interface {
+	BookPickup(domain.Shipment) (Booking, error)
+	Label(trackingNumber string) (Label, error)
+	TrackingEvents(trackingNumber string) ([]TrackingEvent, error)
+	Cancel(trackingNumber string) error
+}
+
+type Registry struct {
+	mu       sync.RWMutex
+	carriers map[string]Carrier
+	routes   map[domain.Route]string
+	fallback string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		carriers: map[string]Carrier{},
+		routes:   map[domain.Route]string{},
+	}
+}
+
+func (r *Registry) Register(name string, c Carrier) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.carriers[name]; ok {
+		return CarrierAlreadyRegistered
+	}
+	r.carriers[name] = c
+
+	return nil
+}
+
+func (r *Registry) Route(route domain.Route, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.carriers[name]; !ok {
+		return UnknownCarrier
+	}
+	r.routes[route] = name
+
+	return nil
+}
+
+// Default sets the carrier used for routes without a specific assignment.
+func (r *Registry) Default(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.carriers[name]; !ok {
+		return UnknownCarrier
+	}
+	r.fallback = name
+
+	return nil
+}
+
+func (r *Registry) Get(name string) (Carrier, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.carriers[name]
+	if !ok {
+		return nil, UnknownCarrier
+	}
+
+	return c, nil
+}
+
+func (r *Registry) ForRoute(route domain.Route) (string, Carrier, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name, ok := r.routes[route]
+	if !ok {
+		name = r.fallback
+	}
+	if name == "" {
+		return "", nil, NoCarrierForRoute
+	}
+
+	return name, r.carriers[name], nil
+}
@@ -0,0 +1,62 @@
+package carrier_test
+
+import (
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/carrier"
+	"github.com/facucachomeli/workshop-go-testing/carrier/fake"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Register_CarrierAlreadyRegistered(t *testing.T) {
+	r := carrier.NewRegistry()
+	r.Register("fake", fake.New("FK"))
+
+	err := r.Register("fake", fake.New("FK"))
+
+	assert.Equal(t, carrier.CarrierAlreadyRegistered, err)
+}
+
+func TestRegistry_Route_UnknownCarrier(t *testing.T) {
+	r := carrier.NewRegistry()
+
+	assert.Equal(t, carrier.UnknownCarrier, r.Route(domain.Route{Origin: "a", Destination: "b"}, "missing"))
+	assert.Equal(t, carrier.UnknownCarrier, r.Default("missing"))
+
+	_, err := r.Get("missing")
+	assert.Equal(t, carrier.UnknownCarrier, err)
+}
+
+func TestRegistry_ForRoute_NoCarrierForRoute(t *testing.T) {
+	r := carrier.NewRegistry()
+	r.Register("fake", fake.New("FK"))
+
+	name, c, err := r.ForRoute(domain.Route{Origin: "a", Destination: "b"})
+
+	assert.Equal(t, carrier.NoCarrierForRoute, err)
+	assert.Empty(t, name)
+	assert.Nil(t, c)
+}
+
+func TestRegistry_ForRoute_OK(t *testing.T) {
+	local := fake.New("LC")
+	international := fake.New("IN")
+	route := domain.Route{Origin: "Buenos Aires", Destination: "Madrid"}
+
+	r := carrier.NewRegistry()
+	r.Register("local", local)
+	r.Register("international", international)
+	r.Default("local")
+	r.Route(route, "international")
+
+	name, c, err := r.ForRoute(route)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "international", name)
+	assert.Equal(t, international, c)
+
+	name, c, err = r.ForRoute(domain.Route{Origin: "Buenos Aires", Destination: "Cordoba"})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "local", name)
+	assert.Equal(t, local, c)
+}
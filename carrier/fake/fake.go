@@ -0,0 +1,127 @@
+package fake
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/carrier"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+// Carrier is an in-memory carrier. Its default behaviour books every
+// pickup with a sequential tracking number; set any of the Func fields to
+// script a different response.
+type Carrier struct {
+	BookPickupFunc     func(domain.Shipment) (carrier.Booking, error)
+	LabelFunc          func(string) (carrier.Label, error)
+	TrackingEventsFunc func(string) ([]carrier.TrackingEvent, error)
+	CancelFunc         func(string) error
+
+	mu        sync.Mutex
+	prefix    string
+	next      int
+	now       func() time.Time
+	bookings  map[string]domain.Shipment
+	events    map[string][]carrier.TrackingEvent
+	cancelled map[string]bool
+}
+
+func New(prefix string) *Carrier {
+	return &Carrier{
+		prefix:    prefix,
+		now:       time.Now,
+		bookings:  map[string]domain.Shipment{},
+		events:    map[string][]carrier.TrackingEvent{},
+		cancelled: map[string]bool{},
+	}
+}
+
+func (c *Carrier) BookPickup(s domain.Shipment) (carrier.Booking, error) {
+	if c.BookPickupFunc != nil {
+		return c.BookPickupFunc(s)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.next++
+	tracking := fmt.Sprintf("%s%08d", c.prefix, c.next)
+	c.bookings[tracking] = s
+	at := c.now()
+	c.events[tracking] = []carrier.TrackingEvent{{Status: "PickupBooked", Location: s.Origin, At: at}}
+
+	return carrier.Booking{TrackingNumber: tracking, PickupAt: at}, nil
+}
+
+func (c *Carrier) Label(tracking string) (carrier.Label, error) {
+	if c.LabelFunc != nil {
+		return c.LabelFunc(tracking)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.bookings[tracking]
+	if !ok {
+		return carrier.Label{}, carrier.UnknownTrackingNumber
+	}
+
+	data := fmt.Sprintf("%s\n%s -> %s\n", tracking, s.Origin, s.Destination)
+	return carrier.Label{Format: "text/plain", Data: []byte(data)}, nil
+}
+
+func (c *Carrier) TrackingEvents(tracking string) ([]carrier.TrackingEvent, error) {
+	if c.TrackingEventsFunc != nil {
+		return c.TrackingEventsFunc(tracking)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	events, ok := c.events[tracking]
+	if !ok {
+		return nil, carrier.UnknownTrackingNumber
+	}
+
+	return append([]carrier.TrackingEvent(nil), events...), nil
+}
+
+func (c *Carrier) Cancel(tracking string) error {
+	if c.CancelFunc != nil {
+		return c.CancelFunc(tracking)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.bookings[tracking]; !ok {
+		return carrier.UnknownTrackingNumber
+	}
+	c.cancelled[tracking] = true
+
+	return nil
+}
+
+// AddTrackingEvent scripts an event returned by TrackingEvents.
+func (c *Carrier) AddTrackingEvent(tracking string, e carrier.TrackingEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.events[tracking] = append(c.events[tracking], e)
+}
+
+func (c *Carrier) Booked(tracking string) (domain.Shipment, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.bookings[tracking]
+	return s, ok
+}
+
+func (c *Carrier) Cancelled(tracking string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cancelled[tracking]
+}
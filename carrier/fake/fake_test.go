@@ -0,0 +1,64 @@
+package fake_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/carrier"
+	"github.com/facucachomeli/workshop-go-testing/carrier/fake"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCarrier_Default_OK(t *testing.T) {
+	c := fake.New("FK")
+	s := domain.Shipment{ID: 1, Origin: "valid origin", Destination: "valid destination"}
+
+	b, err := c.BookPickup(s)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "FK00000001", b.TrackingNumber)
+
+	booked, ok := c.Booked(b.TrackingNumber)
+	assert.True(t, ok)
+	assert.Equal(t, s.ID, booked.ID)
+
+	l, err := c.Label(b.TrackingNumber)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Contains(t, string(l.Data), b.TrackingNumber)
+
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	c.AddTrackingEvent(b.TrackingNumber, carrier.TrackingEvent{Status: "InTransit", Location: "hub", At: at})
+	events, err := c.TrackingEvents(b.TrackingNumber)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "InTransit", events[1].Status)
+	}
+
+	err = c.Cancel(b.TrackingNumber)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.True(t, c.Cancelled(b.TrackingNumber))
+}
+
+func TestCarrier_Default_UnknownTrackingNumber(t *testing.T) {
+	c := fake.New("FK")
+
+	_, err := c.Label("missing")
+	assert.Equal(t, carrier.UnknownTrackingNumber, err)
+
+	_, err = c.TrackingEvents("missing")
+	assert.Equal(t, carrier.UnknownTrackingNumber, err)
+
+	assert.Equal(t, carrier.UnknownTrackingNumber, c.Cancel("missing"))
+}
+
+func TestCarrier_Scripted(t *testing.T) {
+	c := fake.New("FK")
+	c.BookPickupFunc = func(domain.Shipment) (carrier.Booking, error) {
+		return carrier.Booking{}, errors.New("Pickup unavailable")
+	}
+
+	_, err := c.BookPickup(domain.Shipment{ID: 1})
+
+	assert.EqualError(t, err, "Pickup unavailable")
+}
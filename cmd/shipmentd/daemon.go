@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/audit"
+	"github.com/facucachomeli/workshop-go-testing/config"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/grpcapi"
+	"github.com/facucachomeli/workshop-go-testing/httpapi"
+	"github.com/facucachomeli/workshop-go-testing/outbox"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/webhook"
+	"google.golang.org/grpc"
+)
+
+const outboxSize = 1024
+
+// readHeaderTimeout and readTimeout bound how long a client may take to send
+// its request, so slow clients can not hold connections open.
+const readHeaderTimeout = 5 * time.Second
+const readTimeout = 30 * time.Second
+
+// webhookTimeout bounds each webhook request, so a subscriber that never
+// answers can not hold the relay.
+const webhookTimeout = 10 * time.Second
+
+type daemon struct {
+	config          config.Config
+	shutdownTimeout time.Duration
+	slaInterval     time.Duration
+	logger          *log.Logger
+	webhooks        *webhook.Dispatcher
+
+	ready atomic.Bool
+
+	// breached holds the shipments already reported as breached, so each
+	// breach is logged once rather than on every sweep.
+	breached map[breach]bool
+}
+
+type breach struct {
+	tenant domain.TenantID
+	id     domain.ShipmentID
+}
+
+// run serves the HTTP API on listener and the gRPC API on rpcListener until
+// ctx is done, then shuts down in order: stop reporting ready, drain the
+// in-flight requests and calls within the shutdown timeout, closing the
+// connections still open after it, deliver the events still in the outbox
+// within the same timeout, dead-lettering the rest, stop the workers and
+// flush the storage.
+func (d *daemon) run(ctx context.Context, listener net.Listener, rpcListener net.Listener) error {
+	app, err := config.Build(d.config)
+	if err != nil {
+		listener.Close()
+		rpcListener.Close()
+		return err
+	}
+
+	if d.webhooks == nil {
+		d.webhooks = webhook.NewDispatcher(&http.Client{Timeout: webhookTimeout}, 3, time.Second)
+	}
+	for _, w := range d.config.Webhooks {
+		if err := d.webhooks.Subscribe(w.Subscription()); err != nil {
+			listener.Close()
+			rpcListener.Close()
+			return err
+		}
+	}
+	events := outbox.New(outboxSize, d.webhooks.Drop)
+	app.Publish = events.Publish
+	app.AuditFailed = func(e audit.Entry, err error) {
+		d.logger.Printf("could not record audit entry %s of shipment %d for %s: %s", e.Action, e.ShipmentID, e.Actor, err)
+	}
+
+	workers, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var relay, sweeps sync.WaitGroup
+	relay.Add(1)
+	go func() {
+		defer relay.Done()
+		events.Relay(workers, d.webhooks.Notify)
+	}()
+	sweeps.Add(1)
+	go func() {
+		defer sweeps.Done()
+		d.sweep(workers, app)
+	}()
+
+	server := &http.Server{
+		Handler:           d.routes(httpapi.New(app)),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+	}
+	rpc := grpcapi.New(app)
+
+	var serving sync.WaitGroup
+	served := make(chan error, 2)
+	serving.Add(2)
+	go func() {
+		defer serving.Done()
+		served <- server.Serve(listener)
+	}()
+	go func() {
+		defer serving.Done()
+		served <- rpc.Serve(rpcListener)
+	}()
+
+	d.ready.Store(true)
+	d.logger.Printf("listening on %s, gRPC on %s", listener.Addr(), rpcListener.Addr())
+
+	select {
+	case err = <-served:
+		// One server failed, so the other one is stopped too.
+		server.Close()
+		rpc.Stop()
+	case <-ctx.Done():
+		d.logger.Print("shutting down")
+		d.ready.Store(false)
+		err = d.shutdown(server, rpc)
+	}
+	serving.Wait()
+	d.ready.Store(false)
+
+	events.Close()
+	if !wait(&relay, d.shutdownTimeout) {
+		d.logger.Print("outbox not drained in time, dead-lettering the remaining events")
+		stopWorkers()
+		relay.Wait()
+	}
+	stopWorkers()
+	sweeps.Wait()
+
+	if closeErr := app.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// shutdown drains the HTTP requests and gRPC calls in flight together, and
+// closes the connections of the ones still running after the shutdown
+// timeout.
+func (d *daemon) shutdown(server *http.Server, rpc *grpc.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.shutdownTimeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		rpc.GracefulStop()
+		close(stopped)
+	}()
+
+	err := server.Shutdown(ctx)
+	if err == context.DeadlineExceeded {
+		d.logger.Print("requests not drained in time, closing their connections")
+		err = server.Close()
+	}
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		d.logger.Print("calls not drained in time, closing their connections")
+		rpc.Stop()
+		<-stopped
+	}
+
+	return err
+}
+
+// wait reports whether wg finished within timeout.
+func wait(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-t.C:
+		return false
+	}
+}
+
+// routes adds the health and readiness probes to api. The daemon is healthy
+// while the process is serving and ready until it starts shutting down.
+func (d *daemon) routes(api http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", api)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !d.ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return mux
+}
+
+func (d *daemon) sweep(ctx context.Context, app *config.App) {
+	if d.slaInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(d.slaInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.sweepOnce(app, app.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sweepOnce returns the shipments of every tenant that breached their
+// delivery promise at, logging the ones that were not breached on the
+// previous sweep.
+func (d *daemon) sweepOnce(app *config.App, at time.Time) []domain.Shipment {
+	var breached []domain.Shipment
+	seen := map[breach]bool{}
+	for _, tenant := range app.Store.Tenants() {
+		found, err := usecase.NewSLASweepUseCase(app.Store.ForTenant(tenant), app.SLA).Breached(at)
+		if err != nil {
+			d.logger.Printf("could not sweep tenant %s: %s", tenant, err)
+			for b := range d.breached {
+				if b.tenant == tenant {
+					seen[b] = true
+				}
+			}
+			continue
+		}
+		for _, s := range found {
+			b := breach{tenant, s.ID}
+			if !d.breached[b] {
+				d.logger.Printf("tenant %s: shipment %d breached its delivery promise", tenant, s.ID)
+			}
+			seen[b] = true
+		}
+		breached = append(breached, found...)
+	}
+	d.breached = seen
+
+	return breached
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/config"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/grpcapi"
+	"github.com/facucachomeli/workshop-go-testing/httpapi"
+	"github.com/facucachomeli/workshop-go-testing/proto/shipmentpb"
+	"github.com/facucachomeli/workshop-go-testing/storage"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/webhook"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "shipmentd")
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+// newDaemon runs c with an acme merchant keyed "acme-merchant" added to
+// its API clients.
+func newDaemon(c config.Config) *daemon {
+	c.Clients = append(c.Clients, config.Client{Key: "acme-merchant", Tenant: "acme", Actor: "merchant-1", Role: usecase.Merchant})
+
+	return &daemon{
+		config:          c,
+		shutdownTimeout: time.Second,
+		slaInterval:     time.Hour,
+		logger:          log.New(ioutil.Discard, "", 0),
+		webhooks:        webhook.NewDispatcher(http.DefaultClient, 1, 0),
+	}
+}
+
+// start runs d in the background and returns its base URL, its gRPC
+// address and a function that stops it and reports what run returned.
+func start(t *testing.T, d *daemon) (string, string, func() error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	rpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- d.run(ctx, listener, rpcListener)
+	}()
+
+	stop := func() error {
+		// The client may hold a connection it dialed but never used, which
+		// Shutdown would wait on as if it were about to carry a request.
+		http.DefaultClient.CloseIdleConnections()
+		cancel()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected daemon to stop")
+			return nil
+		}
+	}
+	t.Cleanup(func() { cancel() })
+
+	return "http://" + listener.Addr().String(), rpcListener.Addr().String(), stop
+}
+
+func waitReady(t *testing.T, url string) {
+	for i := 0; i < 100; i++ {
+		res, err := http.Get(url + "/readyz")
+		if err == nil {
+			res.Body.Close()
+			if res.StatusCode == http.StatusOK {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("expected daemon to become ready")
+}
+
+func createShipment(t *testing.T, url string) *http.Response {
+	req, _ := http.NewRequest(http.MethodPost, url+"/shipments", bytes.NewBufferString(`{"origin":"valid origin","destination":"valid destination"}`))
+	req.Header.Set(httpapi.AuthorizationHeader, "Bearer acme-merchant")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	res.Body.Close()
+
+	return res
+}
+
+func TestDaemon_ServeAndShutdown(t *testing.T) {
+	var notified, leaked int32
+	hooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&notified, 1)
+	}))
+	defer hooks.Close()
+	otherHooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&leaked, 1)
+	}))
+	defer otherHooks.Close()
+
+	c := config.Default()
+	c.Storage = config.Storage{Backend: config.FileBackend, Path: filepath.Join(tempDir(t), "shipments.json")}
+	c.Webhooks = []config.Webhook{
+		{ID: "acme", Tenant: "acme", URL: hooks.URL, Secret: "secret", Events: []domain.ShipmentState{domain.Created}},
+		{ID: "globex", Tenant: "globex", URL: otherHooks.URL, Secret: "secret", Events: []domain.ShipmentState{domain.Created}},
+	}
+	d := newDaemon(c)
+
+	url, _, stop := start(t, d)
+	waitReady(t, url)
+
+	res, err := http.Get(url + "/healthz")
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = createShipment(t, url)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	err = stop()
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notified), "expected the outbox to be drained on shutdown")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&leaked), "expected other tenants not to be notified")
+	assert.False(t, d.ready.Load())
+
+	reopened, err := storage.OpenFile(c.Storage.Path)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	s, _ := reopened.ForTenant("acme").GetByID(1)
+	assert.Equal(t, domain.Created, s.State)
+
+	_, err = http.Get(url + "/healthz")
+	assert.NotNil(t, err, "expected the listener to be closed")
+}
+
+func TestDaemon_ShutdownWithHangingSubscriber(t *testing.T) {
+	release := make(chan struct{})
+	hooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer hooks.Close()
+	defer close(release)
+
+	d := newDaemon(config.Default())
+	d.shutdownTimeout = 500 * time.Millisecond
+	d.webhooks.Subscribe(webhook.Subscription{ID: "hanging", Tenant: "acme", URL: hooks.URL, Secret: "secret", Events: []domain.ShipmentState{domain.Created}})
+
+	url, _, stop := start(t, d)
+	waitReady(t, url)
+	for i := 0; i < 3; i++ {
+		res := createShipment(t, url)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+	}
+
+	began := time.Now()
+	err := stop()
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.True(t, time.Since(began) < 3*time.Second, "expected shutdown to give up on the subscriber after the timeout")
+	assert.Len(t, d.webhooks.DeadLetters(), 3, "expected every undelivered event to be dead-lettered")
+}
+
+func TestDaemon_ShutdownWithStalledRequest(t *testing.T) {
+	d := newDaemon(config.Default())
+	d.shutdownTimeout = 200 * time.Millisecond
+
+	url, _, stop := start(t, d)
+	waitReady(t, url)
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET /healthz HTTP/1.1\r\nHost: shipmentd\r\n"))
+	time.Sleep(50 * time.Millisecond)
+
+	began := time.Now()
+	err = stop()
+
+	assert.Nilf(t, err, "expected a forced stop not to be an error but got '%s'", err)
+	assert.True(t, time.Since(began) < readHeaderTimeout, "expected the stalled connection to be closed after the shutdown timeout")
+}
+
+func TestDaemon_ServeGRPC(t *testing.T) {
+	d := newDaemon(config.Default())
+
+	url, addr, stop := start(t, d)
+	waitReady(t, url)
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	defer conn.Close()
+	client := shipmentpb.NewShipmentServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), grpcapi.AuthorizationMetadata, "Bearer acme-merchant")
+
+	s, err := client.CreateShipment(ctx, &shipmentpb.CreateShipmentRequest{Origin: "valid origin", Destination: "valid destination"})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, shipmentpb.ShipmentState_SHIPMENT_STATE_CREATED, s.State)
+
+	res := createShipment(t, url)
+	assert.Equal(t, http.StatusCreated, res.StatusCode, "expected both APIs to share the store")
+
+	err = stop()
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	_, err = client.GetShipment(ctx, &shipmentpb.ShipmentRequest{Id: s.Id})
+	assert.Equal(t, codes.Unavailable, status.Code(err), "expected the gRPC server to be stopped")
+}
+
+func TestDaemon_InvalidConfig(t *testing.T) {
+	c := config.Default()
+	c.HTTP.Port = 0
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	rpcListener, _ := net.Listen("tcp", "127.0.0.1:0")
+
+	err := newDaemon(c).run(context.Background(), listener, rpcListener)
+
+	assert.Equal(t, config.InvalidPort, err)
+}
+
+func TestDaemon_Readiness(t *testing.T) {
+	d := newDaemon(config.Default())
+	handler := d.routes(http.NotFoundHandler())
+
+	cases := []struct {
+		name           string
+		ready          bool
+		path           string
+		expectedStatus int
+	}{
+		{name: "Starting", ready: false, path: "/readyz", expectedStatus: http.StatusServiceUnavailable},
+		{name: "Ready", ready: true, path: "/readyz", expectedStatus: http.StatusOK},
+		{name: "Healthy While Draining", ready: false, path: "/healthz", expectedStatus: http.StatusOK},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d.ready.Store(c.ready)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.path, nil))
+
+			assert.Equal(t, c.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestDaemon_SweepOnce(t *testing.T) {
+	app, _ := config.Build(config.Default())
+	app.Now = func() time.Time { return time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC) }
+	app.SLA.Transit[domain.Standard] = 10 * 24 * time.Hour
+	for _, tenant := range []domain.TenantID{"acme", "globex"} {
+		_, err := app.Shipments(tenant).Create(usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}, "valid origin", "valid destination")
+		assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	}
+
+	var logs bytes.Buffer
+	d := newDaemon(config.Default())
+	d.logger = log.New(&logs, "", 0)
+
+	assert.Empty(t, d.sweepOnce(app, app.Now().AddDate(0, 0, 5)), "expected the configured transit time to apply")
+	assert.Len(t, d.sweepOnce(app, app.Now().AddDate(0, 1, 0)), 2)
+	assert.Len(t, d.sweepOnce(app, app.Now().AddDate(0, 1, 1)), 2)
+	assert.Equal(t, 2, strings.Count(logs.String(), "breached its delivery promise"), "expected each breach to be logged once")
+}
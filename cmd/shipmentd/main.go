@@ -0,0 +1,55 @@
+// Command shipmentd serves the shipment API over HTTP and gRPC.
+//
+//	shipmentd -config shipmentd.yaml
+//
+// Configuration is read from the file given with -config and can be
+// overridden with SHIPMENTD_* environment variables.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/config"
+)
+
+func main() {
+	path := flag.String("config", "", "path to the YAML configuration file")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 15*time.Second, "how long to wait for in-flight requests and calls on shutdown")
+	slaInterval := flag.Duration("sla-interval", time.Minute, "how often to sweep for breached delivery promises")
+	flag.Parse()
+
+	c, err := config.LoadFromEnv(*path)
+	if err != nil {
+		log.Fatalf("could not load configuration: %s", err)
+	}
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(c.HTTP.Port))
+	if err != nil {
+		log.Fatalf("could not listen: %s", err)
+	}
+	rpcListener, err := net.Listen("tcp", ":"+strconv.Itoa(c.GRPC.Port))
+	if err != nil {
+		log.Fatalf("could not listen for gRPC: %s", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	d := daemon{
+		config:          c,
+		shutdownTimeout: *shutdownTimeout,
+		slaInterval:     *slaInterval,
+		logger:          log.New(os.Stderr, "shipmentd: ", log.LstdFlags),
+	}
+	if err := d.run(ctx, listener, rpcListener); err != nil {
+		log.Fatal(err)
+	}
+}
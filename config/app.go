@@ -0,0 +1,185 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/audit"
+	"github.com/facucachomeli/workshop-go-testing/carrier"
+	"github.com/facucachomeli/workshop-go-testing/carrier/fake"
+	"github.com/facucachomeli/workshop-go-testing/dangerousgoods"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/storage"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+)
+
+// Shipments is the audited shipment use case every transport talks to.
+type Shipments = audit.ShipmentUseCase
+
+// Holds is the audited hold use case.
+type Holds = audit.HoldUseCase
+
+type App struct {
+	Config   Config
+	Store    *storage.Memory
+	Carriers *carrier.Registry
+	Audit    audit.Sink
+	Policy   usecase.Policy
+	SLA      domain.SLAPolicy
+	Now      func() time.Time
+	Publish  func(domain.Shipment)
+
+	// AuditFailed is told about every audit entry the sink could not take.
+	// It defaults to the standard logger.
+	AuditFailed func(audit.Entry, error)
+}
+
+// Build validates c and wires everything the use cases need.
+func Build(c Config) (*App, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	app := &App{Config: c, Policy: usecase.DefaultPolicy(), SLA: domain.DefaultSLAPolicy(), Now: time.Now}
+
+	app.Carriers = carrier.NewRegistry()
+	for _, cc := range c.Carriers {
+		if err := app.Carriers.Register(cc.Name, fake.New(cc.Prefix)); err != nil {
+			return nil, err
+		}
+	}
+	if c.DefaultCarrier != "" {
+		if err := app.Carriers.Default(c.DefaultCarrier); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.PolicyFile != "" {
+		data, err := ioutil.ReadFile(c.PolicyFile)
+		if err != nil {
+			return nil, err
+		}
+		if app.Policy, err = usecase.ParsePolicy(data); err != nil {
+			return nil, err
+		}
+		if err := checkRoles(c.Clients, app.Policy); err != nil {
+			return nil, err
+		}
+	}
+
+	// The audit sink is the only part holding an open file, so it is opened
+	// last and released if the store can not be loaded. The file store only
+	// touches its file when it is read here and on each save.
+	var err error
+	switch {
+	case !c.Features.Audit:
+		app.Audit = discardSink{}
+	case c.Features.AuditPath != "":
+		if app.Audit, err = audit.NewFileSink(c.Features.AuditPath); err != nil {
+			return nil, err
+		}
+	default:
+		app.Audit = audit.NewMemorySink()
+	}
+
+	if c.Storage.Backend == FileBackend {
+		if app.Store, err = storage.OpenFile(c.Storage.Path); err != nil {
+			if closer, ok := app.Audit.(io.Closer); ok {
+				closer.Close()
+			}
+			return nil, err
+		}
+	} else {
+		app.Store = storage.NewMemory()
+	}
+
+	return app, nil
+}
+
+// Shipments returns the use case scoped to tenant.
+func (a *App) Shipments(tenant domain.TenantID) Shipments {
+	store := a.Store.ForTenant(tenant)
+
+	sequence := store.Next
+	if a.Config.Sequence.Strategy == RandomStrategy {
+		sequence = randomID
+	}
+
+	uc := usecase.NewShipmentUseCase(store.Save, store, sequence).
+		WithClock(a.Now).
+		WithCarriers(a.Carriers).
+		WithPolicy(a.Policy).
+		WithSLAPolicy(a.SLA)
+	if a.Publish != nil {
+		uc = uc.WithPublisher(a.Publish)
+	}
+	if !a.Config.Features.DangerousGoods {
+		uc = uc.WithDangerousGoods(dangerousgoods.NewEngine())
+	}
+
+	audited := audit.NewShipmentUseCase(tenant, uc, a.Audit, a.Now)
+	if a.AuditFailed != nil {
+		audited = audited.WithFailureHandler(a.AuditFailed)
+	}
+
+	return audited
+}
+
+// Holds returns the hold use case scoped to tenant.
+func (a *App) Holds(tenant domain.TenantID) Holds {
+	store := a.Store.ForTenant(tenant)
+	uc := usecase.NewHoldUseCase(store.Save, store, store, a.Now).WithPolicy(a.Policy)
+
+	audited := audit.NewHoldUseCase(tenant, uc, a.Audit, a.Now)
+	if a.AuditFailed != nil {
+		audited = audited.WithFailureHandler(a.AuditFailed)
+	}
+
+	return audited
+}
+
+// Authenticate finds the configured client holding key. Keys are compared
+// in constant time so response times do not leak them.
+func (a *App) Authenticate(key string) (Client, bool) {
+	if key == "" {
+		return Client{}, false
+	}
+	for _, c := range a.Config.Clients {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(c.Key)) == 1 {
+			return c, true
+		}
+	}
+
+	return Client{}, false
+}
+
+// Close flushes the storage and releases the audit sink.
+func (a *App) Close() error {
+	if err := a.Store.Flush(); err != nil {
+		return err
+	}
+	if c, ok := a.Audit.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func randomID() domain.ShipmentID {
+	var b [4]byte
+	rand.Read(b[:])
+	return domain.ShipmentID(binary.BigEndian.Uint32(b[:])>>1) + 1
+}
+
+type discardSink struct{}
+
+func (discardSink) Append(audit.Entry) error {
+	return nil
+}
+
+func (discardSink) Query(audit.Query) ([]audit.Entry, error) {
+	return nil, nil
+}
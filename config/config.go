@@ -0,0 +1,291 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/webhook"
+	"gopkg.in/yaml.v2"
+)
+
+var InvalidConfig = errors.New("Invalid config")
+var InvalidPort = errors.New("Invalid HTTP port")
+var InvalidGRPCPort = errors.New("Invalid gRPC port")
+var InvalidStorageBackend = errors.New("Invalid storage backend")
+var MissingStoragePath = errors.New("Missing storage path")
+var InvalidSequenceStrategy = errors.New("Invalid sequence strategy")
+var InvalidCarrier = errors.New("Invalid carrier")
+var UnknownDefaultCarrier = errors.New("Default carrier is not configured")
+var InvalidEnvOverride = errors.New("Invalid environment override")
+var InvalidWebhook = errors.New("Invalid webhook")
+var InvalidClient = errors.New("Invalid API client")
+
+const EnvPrefix = "SHIPMENTD_"
+
+var MemoryBackend = "memory"
+var FileBackend = "file"
+
+var SequentialStrategy = "sequential"
+var RandomStrategy = "random"
+
+var FakeCarrier = "fake"
+
+type Config struct {
+	HTTP           HTTP      `yaml:"http"`
+	GRPC           GRPC      `yaml:"grpc"`
+	Storage        Storage   `yaml:"storage"`
+	Sequence       Sequence  `yaml:"sequence"`
+	Carriers       []Carrier `yaml:"carriers"`
+	DefaultCarrier string    `yaml:"default_carrier"`
+	PolicyFile     string    `yaml:"policy_file"`
+	Features       Features  `yaml:"features"`
+	Webhooks       []Webhook `yaml:"webhooks"`
+	Clients        []Client  `yaml:"clients"`
+}
+
+type HTTP struct {
+	Port int `yaml:"port"`
+}
+
+type GRPC struct {
+	Port int `yaml:"port"`
+}
+
+type Storage struct {
+	Backend string `yaml:"backend"`
+	Path    string `yaml:"path"`
+}
+
+type Sequence struct {
+	Strategy string `yaml:"strategy"`
+}
+
+type Carrier struct {
+	Name   string `yaml:"name"`
+	Type   string `yaml:"type"`
+	Prefix string `yaml:"prefix"`
+}
+
+// Webhook subscribes URL to the events of one tenant's shipments. The ID
+// names the subscription in dead letters.
+type Webhook struct {
+	ID     string                 `yaml:"id"`
+	Tenant domain.TenantID        `yaml:"tenant"`
+	URL    string                 `yaml:"url"`
+	Secret string                 `yaml:"secret"`
+	Events []domain.ShipmentState `yaml:"events"`
+}
+
+func (w Webhook) Subscription() webhook.Subscription {
+	return webhook.Subscription{ID: w.ID, Tenant: w.Tenant, URL: w.URL, Secret: w.Secret, Events: w.Events}
+}
+
+// Client is a caller of the HTTP and gRPC APIs. Requests carrying Key act as Actor,
+// with Role, on the shipments of Tenant.
+type Client struct {
+	Key    string          `yaml:"key"`
+	Tenant domain.TenantID `yaml:"tenant"`
+	Actor  string          `yaml:"actor"`
+	Role   usecase.Role    `yaml:"role"`
+}
+
+type Features struct {
+	DangerousGoods bool   `yaml:"dangerous_goods"`
+	Audit          bool   `yaml:"audit"`
+	AuditPath      string `yaml:"audit_path"`
+}
+
+func Default() Config {
+	return Config{
+		HTTP:     HTTP{Port: 8080},
+		GRPC:     GRPC{Port: 9090},
+		Storage:  Storage{Backend: MemoryBackend},
+		Sequence: Sequence{Strategy: SequentialStrategy},
+		Features: Features{DangerousGoods: true},
+	}
+}
+
+// Parse reads YAML on top of the defaults, so a config file only needs the
+// settings it changes. Unknown keys are rejected.
+func Parse(data []byte) (Config, error) {
+	c := Default()
+	if err := yaml.UnmarshalStrict(data, &c); err != nil {
+		return Config{}, InvalidConfig
+	}
+
+	return c, nil
+}
+
+// Load parses the file at path, applies the SHIPMENTD_* variables found
+// through lookup and validates the result. An empty path starts from the
+// defaults.
+func Load(path string, lookup func(string) (string, bool)) (Config, error) {
+	c := Default()
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		if c, err = Parse(data); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := c.applyEnv(lookup); err != nil {
+		return Config{}, err
+	}
+	if err := c.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return c, nil
+}
+
+func LoadFromEnv(path string) (Config, error) {
+	return Load(path, os.LookupEnv)
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	texts := map[string]*string{
+		"STORAGE_BACKEND":   &c.Storage.Backend,
+		"STORAGE_PATH":      &c.Storage.Path,
+		"SEQUENCE_STRATEGY": &c.Sequence.Strategy,
+		"DEFAULT_CARRIER":   &c.DefaultCarrier,
+		"POLICY_FILE":       &c.PolicyFile,
+		"AUDIT_PATH":        &c.Features.AuditPath,
+	}
+	for name, field := range texts {
+		if v, ok := lookup(EnvPrefix + name); ok {
+			*field = v
+		}
+	}
+
+	bools := map[string]*bool{
+		"FEATURES_DANGEROUS_GOODS": &c.Features.DangerousGoods,
+		"FEATURES_AUDIT":           &c.Features.Audit,
+	}
+	for name, field := range bools {
+		if v, ok := lookup(EnvPrefix + name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return InvalidEnvOverride
+			}
+			*field = b
+		}
+	}
+
+	ports := map[string]*int{
+		"HTTP_PORT": &c.HTTP.Port,
+		"GRPC_PORT": &c.GRPC.Port,
+	}
+	for name, field := range ports {
+		if v, ok := lookup(EnvPrefix + name); ok {
+			port, err := strconv.Atoi(v)
+			if err != nil {
+				return InvalidEnvOverride
+			}
+			*field = port
+		}
+	}
+
+	// SHIPMENTD_CARRIERS=fast:FST,slow:SLW replaces the configured carriers
+	// with fake carriers using the given tracking prefixes.
+	if v, ok := lookup(EnvPrefix + "CARRIERS"); ok {
+		c.Carriers = nil
+		for _, entry := range splitList(v) {
+			parts := splitPair(entry)
+			c.Carriers = append(c.Carriers, Carrier{Name: parts[0], Type: FakeCarrier, Prefix: parts[1]})
+		}
+	}
+
+	return nil
+}
+
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func splitPair(v string) [2]string {
+	i := strings.Index(v, ":")
+	if i < 0 {
+		return [2]string{v, ""}
+	}
+	return [2]string{v[:i], v[i+1:]}
+}
+
+func (c Config) Validate() error {
+	if c.HTTP.Port < 1 || c.HTTP.Port > 65535 {
+		return InvalidPort
+	}
+	if c.GRPC.Port < 1 || c.GRPC.Port > 65535 || c.GRPC.Port == c.HTTP.Port {
+		return InvalidGRPCPort
+	}
+	switch c.Storage.Backend {
+	case MemoryBackend:
+	case FileBackend:
+		if c.Storage.Path == "" {
+			return MissingStoragePath
+		}
+	default:
+		return InvalidStorageBackend
+	}
+	if c.Sequence.Strategy != SequentialStrategy && c.Sequence.Strategy != RandomStrategy {
+		return InvalidSequenceStrategy
+	}
+
+	names := map[string]bool{}
+	for _, carrier := range c.Carriers {
+		if carrier.Name == "" || carrier.Type != FakeCarrier || names[carrier.Name] {
+			return InvalidCarrier
+		}
+		names[carrier.Name] = true
+	}
+	if c.DefaultCarrier != "" && !names[c.DefaultCarrier] {
+		return UnknownDefaultCarrier
+	}
+
+	ids := map[string]bool{}
+	for _, w := range c.Webhooks {
+		if w.ID == "" || ids[w.ID] || w.Subscription().Validate() != nil {
+			return InvalidWebhook
+		}
+		ids[w.ID] = true
+	}
+
+	keys := map[string]bool{}
+	for _, client := range c.Clients {
+		if client.Key == "" || keys[client.Key] || client.Tenant == "" || client.Actor == "" || client.Role == "" {
+			return InvalidClient
+		}
+		keys[client.Key] = true
+	}
+	// Roles of a policy file are checked once Build has read it.
+	if c.PolicyFile == "" {
+		return checkRoles(c.Clients, usecase.DefaultPolicy())
+	}
+
+	return nil
+}
+
+// checkRoles rejects clients whose role p does not know, which would make
+// every call they make Forbidden.
+func checkRoles(clients []Client, p usecase.Policy) error {
+	for _, client := range clients {
+		if _, ok := p[client.Role]; !ok {
+			return InvalidClient
+		}
+	}
+
+	return nil
+}
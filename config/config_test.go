@@ -0,0 +1,401 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/audit"
+	"github.com/facucachomeli/workshop-go-testing/config"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+func TestLoad_File(t *testing.T) {
+	c, err := config.Load(filepath.Join("testdata", "shipmentd.yaml"), env(nil))
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, config.Config{
+		HTTP:     config.HTTP{Port: 9090},
+		GRPC:     config.GRPC{Port: 9091},
+		Storage:  config.Storage{Backend: config.FileBackend, Path: "/var/lib/shipmentd/shipments.json"},
+		Sequence: config.Sequence{Strategy: config.SequentialStrategy},
+		Carriers: []config.Carrier{
+			{Name: "fast", Type: config.FakeCarrier, Prefix: "FST"},
+			{Name: "slow", Type: config.FakeCarrier, Prefix: "SLW"},
+		},
+		DefaultCarrier: "fast",
+		Features:       config.Features{DangerousGoods: true, Audit: true, AuditPath: "/var/log/shipmentd/audit.log"},
+		Webhooks: []config.Webhook{{
+			ID:     "acme-tracking",
+			Tenant: "acme",
+			URL:    "https://hooks.acme.example/shipments",
+			Secret: "s3cr3t",
+			Events: []domain.ShipmentState{domain.Shipped, domain.Delivered},
+		}},
+		Clients: []config.Client{{Key: "acme-merchant-key", Tenant: "acme", Actor: "merchant-1", Role: usecase.Merchant}},
+	}, c)
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	c, err := config.Load("", env(nil))
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, config.Default(), c)
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	c, err := config.Load(filepath.Join("testdata", "shipmentd.yaml"), env(map[string]string{
+		"SHIPMENTD_HTTP_PORT":                "8081",
+		"SHIPMENTD_GRPC_PORT":                "8082",
+		"SHIPMENTD_STORAGE_BACKEND":          "memory",
+		"SHIPMENTD_SEQUENCE_STRATEGY":        "random",
+		"SHIPMENTD_CARRIERS":                 "local:LCL, ",
+		"SHIPMENTD_DEFAULT_CARRIER":          "local",
+		"SHIPMENTD_FEATURES_DANGEROUS_GOODS": "false",
+		"SHIPMENTD_FEATURES_AUDIT":           "0",
+	}))
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, 8081, c.HTTP.Port)
+	assert.Equal(t, 8082, c.GRPC.Port)
+	assert.Equal(t, config.MemoryBackend, c.Storage.Backend)
+	assert.Equal(t, config.RandomStrategy, c.Sequence.Strategy)
+	assert.Equal(t, []config.Carrier{{Name: "local", Type: config.FakeCarrier, Prefix: "LCL"}}, c.Carriers)
+	assert.Equal(t, "local", c.DefaultCarrier)
+	assert.False(t, c.Features.DangerousGoods)
+	assert.False(t, c.Features.Audit)
+}
+
+func TestLoad_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		yaml          string
+		env           map[string]string
+		expectedError error
+	}{
+		{
+			name:          "Malformed",
+			yaml:          "http: [",
+			expectedError: config.InvalidConfig,
+		},
+		{
+			name:          "Unknown Key",
+			yaml:          "htpp:\n  port: 80\n",
+			expectedError: config.InvalidConfig,
+		},
+		{
+			name:          "Invalid Port",
+			yaml:          "http:\n  port: 70000\n",
+			expectedError: config.InvalidPort,
+		},
+		{
+			name:          "Invalid gRPC Port",
+			yaml:          "grpc:\n  port: 0\n",
+			expectedError: config.InvalidGRPCPort,
+		},
+		{
+			name:          "Shared Port",
+			yaml:          "http:\n  port: 8080\ngrpc:\n  port: 8080\n",
+			expectedError: config.InvalidGRPCPort,
+		},
+		{
+			name:          "Invalid Storage Backend",
+			yaml:          "storage:\n  backend: postgres\n",
+			expectedError: config.InvalidStorageBackend,
+		},
+		{
+			name:          "Missing Storage Path",
+			yaml:          "storage:\n  backend: file\n",
+			expectedError: config.MissingStoragePath,
+		},
+		{
+			name:          "Invalid Sequence Strategy",
+			yaml:          "sequence:\n  strategy: uuid\n",
+			expectedError: config.InvalidSequenceStrategy,
+		},
+		{
+			name:          "Invalid Carrier Type",
+			yaml:          "carriers:\n  - name: dhl\n    type: dhl\n",
+			expectedError: config.InvalidCarrier,
+		},
+		{
+			name:          "Duplicate Carrier",
+			yaml:          "carriers:\n  - name: fast\n    type: fake\n  - name: fast\n    type: fake\n",
+			expectedError: config.InvalidCarrier,
+		},
+		{
+			name:          "Unknown Default Carrier",
+			yaml:          "default_carrier: fast\n",
+			expectedError: config.UnknownDefaultCarrier,
+		},
+		{
+			name:          "Webhook Without ID",
+			yaml:          "webhooks:\n  - tenant: acme\n    url: https://hooks.acme.example\n    secret: s3cr3t\n    events: [Shipped]\n",
+			expectedError: config.InvalidWebhook,
+		},
+		{
+			name:          "Duplicate Webhook",
+			yaml:          "webhooks:\n  - id: acme\n    tenant: acme\n    url: https://hooks.acme.example\n    secret: s3cr3t\n    events: [Shipped]\n  - id: acme\n    tenant: acme\n    url: https://hooks.acme.example\n    secret: s3cr3t\n    events: [Shipped]\n",
+			expectedError: config.InvalidWebhook,
+		},
+		{
+			name:          "Invalid Webhook URL",
+			yaml:          "webhooks:\n  - id: acme\n    tenant: acme\n    url: hooks.acme.example\n    secret: s3cr3t\n    events: [Shipped]\n",
+			expectedError: config.InvalidWebhook,
+		},
+		{
+			name:          "Invalid Webhook Event",
+			yaml:          "webhooks:\n  - id: acme\n    tenant: acme\n    url: https://hooks.acme.example\n    secret: s3cr3t\n    events: [Lost]\n",
+			expectedError: config.InvalidConfig,
+		},
+		{
+			name:          "Client Without Key",
+			yaml:          "clients:\n  - tenant: acme\n    actor: merchant-1\n    role: merchant\n",
+			expectedError: config.InvalidClient,
+		},
+		{
+			name:          "Client Without Tenant",
+			yaml:          "clients:\n  - key: k\n    actor: merchant-1\n    role: merchant\n",
+			expectedError: config.InvalidClient,
+		},
+		{
+			name:          "Client With Unknown Role",
+			yaml:          "clients:\n  - key: k\n    tenant: acme\n    actor: merchant-1\n    role: merchnat\n",
+			expectedError: config.InvalidClient,
+		},
+		{
+			name:          "Duplicate Client Key",
+			yaml:          "clients:\n  - key: k\n    tenant: acme\n    actor: merchant-1\n    role: merchant\n  - key: k\n    tenant: globex\n    actor: admin-1\n    role: admin\n",
+			expectedError: config.InvalidClient,
+		},
+		{
+			name:          "Invalid Port Override",
+			env:           map[string]string{"SHIPMENTD_HTTP_PORT": "eighty"},
+			expectedError: config.InvalidEnvOverride,
+		},
+		{
+			name:          "Invalid gRPC Port Override",
+			env:           map[string]string{"SHIPMENTD_GRPC_PORT": "grpc"},
+			expectedError: config.InvalidEnvOverride,
+		},
+		{
+			name:          "Invalid Feature Override",
+			env:           map[string]string{"SHIPMENTD_FEATURES_AUDIT": "maybe"},
+			expectedError: config.InvalidEnvOverride,
+		},
+		{
+			name:          "Override Breaks Validation",
+			env:           map[string]string{"SHIPMENTD_STORAGE_BACKEND": "file"},
+			expectedError: config.MissingStoragePath,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(tempDir(t), "shipmentd.yaml")
+			ioutil.WriteFile(path, []byte(c.yaml), 0600)
+
+			_, err := config.Load(path, env(c.env))
+			assert.Equal(t, c.expectedError, err)
+		})
+	}
+}
+
+func TestBuild_Shipments(t *testing.T) {
+	c := config.Default()
+	c.Carriers = []config.Carrier{{Name: "fast", Type: config.FakeCarrier, Prefix: "FST"}}
+	c.DefaultCarrier = "fast"
+	c.Features.Audit = true
+
+	app, err := config.Build(c)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	defer app.Close()
+
+	admin := usecase.Actor{ID: "admin-1", Role: usecase.Admin}
+	shipments := app.Shipments("acme")
+	s, err := shipments.Create(admin, "valid origin", "valid destination")
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	_, err = shipments.Handle(admin, s.ID)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	s, err = shipments.Ship(admin, s.ID)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "fast", s.Carrier)
+	assert.Equal(t, "FST00000001", s.TrackingNumber)
+
+	_, err = app.Shipments("globex").Handle(admin, s.ID)
+	assert.Equal(t, usecase.ShipmentDoesNotExist, err)
+
+	entries, err := app.Audit.Query(audit.Query{Actor: "admin-1"})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Len(t, entries, 4)
+	entries, _ = app.Audit.Query(audit.Query{Tenant: "globex"})
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "Handle", entries[0].Action)
+	}
+}
+
+func TestBuild_Holds(t *testing.T) {
+	c := config.Default()
+	c.Features.Audit = true
+
+	app, err := config.Build(c)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	defer app.Close()
+
+	admin := usecase.Actor{ID: "admin-1", Role: usecase.Admin}
+	s, _ := app.Shipments("acme").Create(admin, "valid origin", "valid destination")
+	s, err = app.Holds("acme").PlaceHold(admin, s.ID, "address check")
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.True(t, s.IsOnHold())
+
+	_, err = app.Shipments("acme").Handle(admin, s.ID)
+	assert.Equal(t, usecase.ShipmentOnHold, err)
+
+	entries, _ := app.Audit.Query(audit.Query{Action: "PlaceHold"})
+	assert.Len(t, entries, 1)
+}
+
+func TestApp_Authenticate(t *testing.T) {
+	c := config.Default()
+	merchant := config.Client{Key: "acme-merchant", Tenant: "acme", Actor: "merchant-1", Role: usecase.Merchant}
+	c.Clients = []config.Client{merchant}
+	app, _ := config.Build(c)
+
+	cases := []struct {
+		name     string
+		key      string
+		expected config.Client
+		ok       bool
+	}{
+		{name: "Known Key", key: "acme-merchant", expected: merchant, ok: true},
+		{name: "Unknown Key", key: "acme-admin"},
+		{name: "Prefix Of Key", key: "acme"},
+		{name: "Empty Key", key: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, ok := app.Authenticate(tc.key)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, client)
+		})
+	}
+}
+
+func TestBuild_FileBackend(t *testing.T) {
+	dir := tempDir(t)
+	c := config.Default()
+	c.Storage = config.Storage{Backend: config.FileBackend, Path: filepath.Join(dir, "shipments.json")}
+	c.Features.Audit = true
+	c.Features.AuditPath = filepath.Join(dir, "audit.log")
+	admin := usecase.Actor{ID: "admin-1", Role: usecase.Admin}
+
+	app, err := config.Build(c)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	s, err := app.Shipments("acme").Create(admin, "valid origin", "valid destination")
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Nil(t, app.Close())
+
+	reopened, err := config.Build(c)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	defer reopened.Close()
+	found, _ := reopened.Store.ForTenant("acme").GetByID(s.ID)
+	assert.Equal(t, domain.Created, found.State)
+	entries, _ := reopened.Audit.Query(audit.Query{Action: "Create"})
+	assert.Len(t, entries, 1)
+}
+
+func TestBuild_PolicyFile(t *testing.T) {
+	path := filepath.Join(tempDir(t), "policy.yaml")
+	ioutil.WriteFile(path, []byte("roles:\n  driver: [create]\n"), 0600)
+	c := config.Default()
+	c.PolicyFile = path
+
+	app, err := config.Build(c)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+
+	_, err = app.Shipments("acme").Create(usecase.Actor{ID: "driver-1", Role: usecase.Driver}, "valid origin", "valid destination")
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	_, err = app.Shipments("acme").Create(usecase.Actor{ID: "admin-1", Role: usecase.Admin}, "valid origin", "valid destination")
+	assert.Equal(t, usecase.Forbidden, err)
+}
+
+func TestBuild_PolicyFile_UnknownClientRole(t *testing.T) {
+	path := filepath.Join(tempDir(t), "policy.yaml")
+	ioutil.WriteFile(path, []byte("roles:\n  driver: [create]\n"), 0600)
+	c := config.Default()
+	c.PolicyFile = path
+	c.Clients = []config.Client{{Key: "acme-admin", Tenant: "acme", Actor: "admin-1", Role: usecase.Admin}}
+
+	app, err := config.Build(c)
+
+	assert.Equal(t, config.InvalidClient, err)
+	assert.Nil(t, app)
+}
+
+func TestBuild_DangerousGoodsDisabled(t *testing.T) {
+	c := config.Default()
+	c.Features.DangerousGoods = false
+	explosive := domain.Parcel{Weight: 500, DangerousGoods: []domain.DangerousGood{{UNNumber: "UN0012", Class: 1, Description: "Cartridges"}}}
+	admin := usecase.Actor{ID: "admin-1", Role: usecase.Admin}
+
+	app, _ := config.Build(c)
+	_, err := app.Shipments("acme").Create(admin, "valid origin", "valid destination", usecase.WithParcels(explosive))
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+
+	app, _ = config.Build(config.Default())
+	_, err = app.Shipments("acme").Create(admin, "valid origin", "valid destination", usecase.WithParcels(explosive))
+	assert.NotNilf(t, err, "expected error but found none")
+}
+
+func TestBuild_RandomSequence(t *testing.T) {
+	c := config.Default()
+	c.Sequence.Strategy = config.RandomStrategy
+	admin := usecase.Actor{ID: "admin-1", Role: usecase.Admin}
+
+	app, _ := config.Build(c)
+	s, err := app.Shipments("acme").Create(admin, "valid origin", "valid destination")
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.True(t, s.ID > 0, "expected a positive ID but got %d", s.ID)
+}
+
+func TestBuild_InvalidConfig(t *testing.T) {
+	c := config.Default()
+	c.HTTP.Port = 0
+
+	app, err := config.Build(c)
+
+	assert.Equal(t, config.InvalidPort, err)
+	assert.Nil(t, app)
+}
@@ -0,0 +1,58 @@
+package customs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+var MissingDeclaration = errors.New("Shipment has no customs declaration")
+
+// WriteCommercialInvoice renders the customs declaration of the shipment as
+// a plain text commercial invoice.
+func WriteCommercialInvoice(w io.Writer, s domain.Shipment) error {
+	if s.Customs == nil {
+		return MissingDeclaration
+	}
+	d := *s.Customs
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "COMMERCIAL INVOICE\n\n")
+	fmt.Fprintf(tw, "Shipment:\t%d\n", s.ID)
+	fmt.Fprintf(tw, "Tracking number:\t%s\n", s.TrackingNumber)
+	fmt.Fprintf(tw, "Incoterm:\t%s\n", d.Incoterm)
+	fmt.Fprintf(tw, "Currency:\t%s\n\n", d.Currency)
+	writeParty(tw, "Exporter", d.Exporter)
+	writeParty(tw, "Importer", d.Importer)
+
+	fmt.Fprintf(tw, "HS code\tDescription\tQuantity\tUnit value\tValue\n")
+	for _, i := range d.Items {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", i.HSCode, i.Description, i.Quantity, amount(i.UnitValue), amount(int64(i.Quantity)*i.UnitValue))
+	}
+	fmt.Fprintf(tw, "\t\t\tTotal\t%s %s\n", amount(d.TotalValue()), d.Currency)
+
+	return tw.Flush()
+}
+
+func writeParty(w io.Writer, role string, p domain.Party) {
+	fmt.Fprintf(w, "%s:\t%s\n", role, p.Name)
+	fmt.Fprintf(w, "\t%s\n", p.Address)
+	fmt.Fprintf(w, "\t%s\n", p.Country)
+	if p.TaxID != "" {
+		fmt.Fprintf(w, "\tTax ID %s\n", p.TaxID)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+func amount(minor int64) string {
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, minor/100, minor%100)
+}
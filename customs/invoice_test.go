@@ -0,0 +1,48 @@
+package customs_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/customs"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/internal/golden"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCommercialInvoice_MissingDeclaration(t *testing.T) {
+	var b bytes.Buffer
+
+	err := customs.WriteCommercialInvoice(&b, domain.Shipment{ID: 1})
+
+	assert.Equal(t, customs.MissingDeclaration, err)
+	assert.Zero(t, b.Len())
+}
+
+func TestWriteCommercialInvoice_Golden(t *testing.T) {
+	s := domain.Shipment{
+		ID:                 domain.ShipmentID(1),
+		State:              domain.Shipped,
+		Origin:             "Buenos Aires",
+		Destination:        "Madrid",
+		OriginCountry:      "AR",
+		DestinationCountry: "ES",
+		TrackingNumber:     "FK00000001",
+		Customs: &domain.CustomsDeclaration{
+			Items: []domain.CustomsItem{
+				{HSCode: "6109.10", Description: "Cotton t-shirt", Quantity: 3, UnitValue: 1250},
+				{HSCode: "420221", Description: "Leather handbag", Quantity: 1, UnitValue: 8900},
+			},
+			Currency: "USD",
+			Incoterm: domain.DAP,
+			Exporter: domain.Party{Name: "Exporter SA", Address: "Av. Corrientes 1234, Buenos Aires", Country: "AR", TaxID: "30-12345678-9"},
+			Importer: domain.Party{Name: "Importer SL", Address: "Gran Via 1, Madrid", Country: "ES"},
+		},
+	}
+	var b bytes.Buffer
+
+	err := customs.WriteCommercialInvoice(&b, s)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+
+	golden.Assert(t, "invoice.txt", b.Bytes())
+}
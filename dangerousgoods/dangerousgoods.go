@@ -0,0 +1,113 @@
+package dangerousgoods
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+type Rule struct {
+	Name   string
+	Reason string
+	// Forbids reports whether the good can not travel on the shipment route
+	// using the given transport mode.
+	Forbids func(g domain.DangerousGood, s domain.Shipment, mode domain.TransportMode) bool
+}
+
+type Violation struct {
+	Rule     string
+	Reason   string
+	Parcel   int
+	UNNumber string
+	Mode     domain.TransportMode
+}
+
+type Violations []Violation
+
+func (v Violations) Error() string {
+	msgs := make([]string, len(v))
+	for i, violation := range v {
+		msgs[i] = fmt.Sprintf("%s: %s (parcel %d, %s, %s)", violation.Rule, violation.Reason, violation.Parcel, violation.UNNumber, violation.Mode)
+	}
+
+	return "Dangerous goods restrictions violated: " + strings.Join(msgs, "; ")
+}
+
+type Engine struct {
+	rules []Rule
+}
+
+func NewEngine(rules ...Rule) Engine {
+	return Engine{rules}
+}
+
+// Check returns Violations listing every rule broken by any dangerous good
+// in the shipment parcels, or nil when the shipment can travel.
+func (e Engine) Check(s domain.Shipment) error {
+	var violations Violations
+	for _, mode := range s.TransportModes() {
+		for i, p := range s.Parcels {
+			for _, g := range p.DangerousGoods {
+				for _, r := range e.rules {
+					if r.Forbids(g, s, mode) {
+						violations = append(violations, Violation{r.Name, r.Reason, i, g.UNNumber, mode})
+					}
+				}
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return violations
+}
+
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:   "explosives",
+			Reason: "explosives are not accepted",
+			Forbids: func(g domain.DangerousGood, _ domain.Shipment, _ domain.TransportMode) bool {
+				return g.Class == 1
+			},
+		},
+		{
+			Name:   "radioactive",
+			Reason: "radioactive material is not accepted",
+			Forbids: func(g domain.DangerousGood, _ domain.Shipment, _ domain.TransportMode) bool {
+				return g.Class == 7
+			},
+		},
+		{
+			Name:   "air-lithium-ion-batteries",
+			Reason: "lithium ion batteries packed alone can not travel by air",
+			Forbids: func(g domain.DangerousGood, _ domain.Shipment, mode domain.TransportMode) bool {
+				return mode == domain.Air && g.UNNumber == "UN3480"
+			},
+		},
+		{
+			Name:   "air-lithium-metal-batteries",
+			Reason: "lithium metal batteries packed alone can not travel by air",
+			Forbids: func(g domain.DangerousGood, _ domain.Shipment, mode domain.TransportMode) bool {
+				return mode == domain.Air && g.UNNumber == "UN3090"
+			},
+		},
+		{
+			Name:   "air-flammables",
+			Reason: "flammable gases and liquids can not travel by air",
+			Forbids: func(g domain.DangerousGood, _ domain.Shipment, mode domain.TransportMode) bool {
+				return mode == domain.Air && (g.Class == 2 || g.Class == 3)
+			},
+		},
+		{
+			Name:   "international-toxics",
+			Reason: "toxic and infectious substances can not cross borders",
+			Forbids: func(g domain.DangerousGood, s domain.Shipment, _ domain.TransportMode) bool {
+				return g.Class == 6 && s.IsInternational()
+			},
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package dangerousgoods_test
+
+import (
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/dangerousgoods"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func shipmentWith(goods ...domain.DangerousGood) domain.Shipment {
+	return domain.Shipment{
+		ID:          1,
+		Origin:      "Buenos Aires",
+		Destination: "Madrid",
+		Parcels:     []domain.Parcel{{Weight: 1000, DangerousGoods: goods}},
+	}
+}
+
+var lithiumIon = domain.DangerousGood{UNNumber: "UN3480", Class: 9}
+var lithiumMetal = domain.DangerousGood{UNNumber: "UN3090", Class: 9}
+var lithiumInEquipment = domain.DangerousGood{UNNumber: "UN3481", Class: 9}
+var paint = domain.DangerousGood{UNNumber: "UN1263", Class: 3}
+var fireworks = domain.DangerousGood{UNNumber: "UN0336", Class: 1}
+var pesticide = domain.DangerousGood{UNNumber: "UN2588", Class: 6}
+
+func TestEngine_Check_OK(t *testing.T) {
+	engine := dangerousgoods.NewEngine(dangerousgoods.DefaultRules()...)
+
+	cases := []struct {
+		name     string
+		shipment domain.Shipment
+	}{
+		{
+			name:     "No Dangerous Goods",
+			shipment: shipmentWith(),
+		},
+		{
+			name:     "Lithium Ion By Ground",
+			shipment: shipmentWith(lithiumIon, paint),
+		},
+		{
+			name: "Lithium In Equipment By Air",
+			shipment: func() domain.Shipment {
+				s := shipmentWith(lithiumInEquipment)
+				s.Legs = []domain.Leg{{Mode: domain.Air}}
+				return s
+			}(),
+		},
+		{
+			name:     "Domestic Toxics",
+			shipment: shipmentWith(pesticide),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := engine.Check(c.shipment)
+			assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+		})
+	}
+}
+
+func TestEngine_Check_Violations(t *testing.T) {
+	engine := dangerousgoods.NewEngine(dangerousgoods.DefaultRules()...)
+	s := shipmentWith(lithiumIon, lithiumMetal, paint, fireworks, pesticide)
+	s.OriginCountry = "AR"
+	s.DestinationCountry = "ES"
+	s.Legs = []domain.Leg{{Mode: domain.Ground}, {Mode: domain.Air}}
+
+	err := engine.Check(s)
+
+	violations, ok := err.(dangerousgoods.Violations)
+	if !ok {
+		t.Fatalf("expected violations but got %#v", err)
+	}
+	var rules []string
+	for _, v := range violations {
+		rules = append(rules, v.Rule+"/"+string(v.Mode))
+	}
+	assert.Equal(t, []string{
+		"explosives/Ground",
+		"international-toxics/Ground",
+		"air-lithium-ion-batteries/Air",
+		"air-lithium-metal-batteries/Air",
+		"air-flammables/Air",
+		"explosives/Air",
+		"international-toxics/Air",
+	}, rules)
+	assert.Contains(t, err.Error(), "air-lithium-ion-batteries: lithium ion batteries packed alone can not travel by air (parcel 0, UN3480, Air)")
+	assert.Contains(t, err.Error(), "air-lithium-metal-batteries: lithium metal batteries packed alone can not travel by air (parcel 0, UN3090, Air)")
+}
+
+func TestEngine_Check_CustomRules(t *testing.T) {
+	engine := dangerousgoods.NewEngine(dangerousgoods.Rule{
+		Name:   "no-sea",
+		Reason: "no sea freight",
+		Forbids: func(_ domain.DangerousGood, _ domain.Shipment, mode domain.TransportMode) bool {
+			return mode == domain.Sea
+		},
+	})
+	s := shipmentWith(lithiumInEquipment)
+
+	assert.Nil(t, engine.Check(s))
+
+	s.Legs = []domain.Leg{{Mode: domain.Sea}}
+	assert.Error(t, engine.Check(s))
+}
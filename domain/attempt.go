@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type FailureReason string
+
+var RecipientAbsent = FailureReason("RecipientAbsent")
+var AddressNotFound = FailureReason("AddressNotFound")
+var Refused = FailureReason("Refused")
+
+var InvalidFailureReason = errors.New("Invalid failure reason")
+var InvalidMaxAttempts = errors.New("Invalid max attempts")
+var InvalidStateForAttempt = errors.New("Shipment is not out for delivery")
+
+type DeliveryAttempt struct {
+	Reason FailureReason `json:"reason" yaml:"reason"`
+	At     time.Time     `json:"at" yaml:"at"`
+}
+
+func (r FailureReason) IsValid() bool {
+	return r == RecipientAbsent || r == AddressNotFound || r == Refused
+}
+
+// FailDelivery records a failed attempt. A refused delivery, or reaching
+// maxAttempts, moves the shipment to DeliveryFailed so it can be returned.
+func (s *Shipment) FailDelivery(reason FailureReason, at time.Time, maxAttempts int) error {
+	if s.IsOnHold() {
+		return ShipmentOnHold
+	}
+	if maxAttempts < 1 {
+		return InvalidMaxAttempts
+	}
+	if !reason.IsValid() {
+		return InvalidFailureReason
+	}
+	if s.State != Shipped {
+		return InvalidStateForAttempt
+	}
+	if !s.hasArrived() {
+		return LegsNotArrived
+	}
+
+	s.Attempts = append(s.Attempts, DeliveryAttempt{reason, at})
+	if reason == Refused || len(s.Attempts) >= maxAttempts {
+		s.State = DeliveryFailed
+	}
+
+	return nil
+}
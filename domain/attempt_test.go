@@ -0,0 +1,93 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShipment_FailDelivery_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		state         domain.ShipmentState
+		reason        domain.FailureReason
+		maxAttempts   int
+		expectedError error
+	}{
+		{
+			name:          "Invalid Max Attempts",
+			state:         domain.Shipped,
+			reason:        domain.RecipientAbsent,
+			maxAttempts:   0,
+			expectedError: domain.InvalidMaxAttempts,
+		},
+		{
+			name:          "Invalid Failure Reason",
+			state:         domain.Shipped,
+			reason:        domain.FailureReason("Lost"),
+			maxAttempts:   3,
+			expectedError: domain.InvalidFailureReason,
+		},
+		{
+			name:          "Invalid State Handled",
+			state:         domain.Handled,
+			reason:        domain.RecipientAbsent,
+			maxAttempts:   3,
+			expectedError: domain.InvalidStateForAttempt,
+		},
+		{
+			name:          "Invalid State Delivered",
+			state:         domain.Delivered,
+			reason:        domain.RecipientAbsent,
+			maxAttempts:   3,
+			expectedError: domain.InvalidStateForAttempt,
+		},
+	}
+
+	var s domain.Shipment
+	for _, c := range cases {
+		s = domain.Shipment{
+			State: c.state,
+		}
+		t.Run(c.name, func(t *testing.T) {
+			err := s.FailDelivery(c.reason, time.Now(), c.maxAttempts)
+			assert.Equal(t, c.state, s.State)
+			assert.Empty(t, s.Attempts)
+			assert.NotNilf(t, err, "expected error but found none")
+			assert.Equal(t, c.expectedError, err)
+		})
+	}
+}
+
+func TestShipment_FailDelivery_OK(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	s := domain.Shipment{
+		State: domain.Shipped,
+	}
+
+	err := s.FailDelivery(domain.RecipientAbsent, at, 2)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.Shipped, s.State)
+
+	err = s.FailDelivery(domain.AddressNotFound, at.Add(24*time.Hour), 2)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.DeliveryFailed, s.State)
+	assert.Equal(t, []domain.DeliveryAttempt{
+		{Reason: domain.RecipientAbsent, At: at},
+		{Reason: domain.AddressNotFound, At: at.Add(24 * time.Hour)},
+	}, s.Attempts)
+}
+
+func TestShipment_FailDelivery_Refused(t *testing.T) {
+	s := domain.Shipment{
+		State: domain.Shipped,
+	}
+
+	err := s.FailDelivery(domain.Refused, time.Now(), 3)
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.DeliveryFailed, s.State)
+	assert.Len(t, s.Attempts, 1)
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type CancellationReason string
+
+var CustomerRequest = CancellationReason("CustomerRequest")
+var AddressIssue = CancellationReason("AddressIssue")
+var OutOfStock = CancellationReason("OutOfStock")
+var FraudSuspected = CancellationReason("FraudSuspected")
+var DuplicateOrder = CancellationReason("DuplicateOrder")
+
+var InvalidCancellationReason = errors.New("Invalid cancellation reason")
+var InvalidStateForCancel = errors.New("Shipment can not be cancelled once shipped")
+var ShipmentAlreadyCancelled = errors.New("Shipment is already cancelled")
+
+type FeeSchedule struct {
+	Currency string                  `json:"currency" yaml:"currency"`
+	Fees     map[ShipmentState]int64 `json:"fees" yaml:"fees"` // in minor units of Currency
+}
+
+type Cancellation struct {
+	Reason    CancellationReason `json:"reason" yaml:"reason"`
+	FromState ShipmentState      `json:"from_state" yaml:"from_state"`
+	Fee       int64              `json:"fee" yaml:"fee"`
+	Currency  string             `json:"currency" yaml:"currency"`
+	At        time.Time          `json:"at" yaml:"at"`
+
+	RefundRequested bool `json:"refund_requested" yaml:"refund_requested"`
+}
+
+// RefundInstruction asks billing to refund a cancelled shipment, keeping the
+// cancellation fee.
+type RefundInstruction struct {
+	ShipmentID ShipmentID
+	Reason     CancellationReason
+	Fee        int64
+	Currency   string
+}
+
+func DefaultFeeSchedule() FeeSchedule {
+	return FeeSchedule{
+		Currency: "USD",
+		Fees: map[ShipmentState]int64{
+			Created: 0,
+			Handled: 500,
+		},
+	}
+}
+
+func (r CancellationReason) IsValid() bool {
+	switch r {
+	case CustomerRequest, AddressIssue, OutOfStock, FraudSuspected, DuplicateOrder:
+		return true
+	}
+
+	return false
+}
+
+// Cancel is only allowed before the shipment leaves the warehouse. The fee
+// charged grows with how far the shipment progressed.
+func (s *Shipment) Cancel(reason CancellationReason, fees FeeSchedule, at time.Time) error {
+	if s.IsOnHold() {
+		return ShipmentOnHold
+	}
+	if !reason.IsValid() {
+		return InvalidCancellationReason
+	}
+	if s.State == Cancelled {
+		return ShipmentAlreadyCancelled
+	}
+	if s.State != Created && s.State != Handled {
+		return InvalidStateForCancel
+	}
+
+	s.Cancellation = &Cancellation{
+		Reason:    reason,
+		FromState: s.State,
+		Fee:       fees.Fees[s.State],
+		Currency:  fees.Currency,
+		At:        at,
+	}
+	s.State = Cancelled
+
+	return nil
+}
+
+// HasPendingRefund reports whether the shipment was cancelled but its refund
+// was never handed off.
+func (s Shipment) HasPendingRefund() bool {
+	return s.Cancellation != nil && !s.Cancellation.RefundRequested
+}
+
+func (s *Shipment) MarkRefundRequested() {
+	if s.Cancellation == nil {
+		return
+	}
+	c := *s.Cancellation
+	c.RefundRequested = true
+	s.Cancellation = &c
+}
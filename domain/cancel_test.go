@@ -0,0 +1,109 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShipment_Cancel_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		state         domain.ShipmentState
+		reason        domain.CancellationReason
+		expectedError error
+	}{
+		{
+			name:          "Invalid Reason",
+			state:         domain.Created,
+			reason:        domain.CancellationReason("Bored"),
+			expectedError: domain.InvalidCancellationReason,
+		},
+		{
+			name:          "Invalid State Shipped",
+			state:         domain.Shipped,
+			reason:        domain.CustomerRequest,
+			expectedError: domain.InvalidStateForCancel,
+		},
+		{
+			name:          "Invalid State Delivered",
+			state:         domain.Delivered,
+			reason:        domain.CustomerRequest,
+			expectedError: domain.InvalidStateForCancel,
+		},
+		{
+			name:          "Invalid State Cancelled",
+			state:         domain.Cancelled,
+			reason:        domain.CustomerRequest,
+			expectedError: domain.ShipmentAlreadyCancelled,
+		},
+	}
+
+	var s domain.Shipment
+	for _, c := range cases {
+		s = domain.Shipment{
+			State: c.state,
+		}
+		t.Run(c.name, func(t *testing.T) {
+			err := s.Cancel(c.reason, domain.DefaultFeeSchedule(), time.Now())
+			assert.Equal(t, c.state, s.State)
+			assert.Nil(t, s.Cancellation)
+			assert.NotNilf(t, err, "expected error but found none")
+			assert.Equal(t, c.expectedError, err)
+		})
+	}
+}
+
+func TestShipment_Cancel_OK(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name        string
+		state       domain.ShipmentState
+		expectedFee int64
+	}{
+		{
+			name:        "Created",
+			state:       domain.Created,
+			expectedFee: 0,
+		},
+		{
+			name:        "Handled",
+			state:       domain.Handled,
+			expectedFee: 500,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := domain.Shipment{
+				State: c.state,
+			}
+			err := s.Cancel(domain.CustomerRequest, domain.DefaultFeeSchedule(), at)
+
+			assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+			assert.Equal(t, domain.Cancelled, s.State)
+			assert.Equal(t, &domain.Cancellation{
+				Reason:    domain.CustomerRequest,
+				FromState: c.state,
+				Fee:       c.expectedFee,
+				Currency:  "USD",
+				At:        at,
+			}, s.Cancellation)
+		})
+	}
+}
+
+func TestShipment_MarkRefundRequested(t *testing.T) {
+	s := domain.Shipment{State: domain.Created}
+	assert.False(t, s.HasPendingRefund())
+
+	s.Cancel(domain.CustomerRequest, domain.DefaultFeeSchedule(), time.Time{})
+	cancellation := s.Cancellation
+	assert.True(t, s.HasPendingRefund())
+
+	s.MarkRefundRequested()
+	assert.False(t, s.HasPendingRefund())
+	assert.False(t, cancellation.RefundRequested, "expected the previous cancellation not to be modified")
+}
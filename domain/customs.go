@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+type Incoterm string
+
+var EXW = Incoterm("EXW")
+var FCA = Incoterm("FCA")
+var CPT = Incoterm("CPT")
+var CIP = Incoterm("CIP")
+var DAP = Incoterm("DAP")
+var DPU = Incoterm("DPU")
+var DDP = Incoterm("DDP")
+var FAS = Incoterm("FAS")
+var FOB = Incoterm("FOB")
+var CFR = Incoterm("CFR")
+var CIF = Incoterm("CIF")
+
+var CustomsDeclarationRequired = errors.New("Customs declaration required")
+var MissingCustomsItems = errors.New("Customs declaration has no items")
+var InvalidHSCode = errors.New("Invalid HS code")
+var InvalidItemDescription = errors.New("Invalid item description")
+var InvalidItemQuantity = errors.New("Invalid item quantity")
+var InvalidItemValue = errors.New("Invalid item value")
+var InvalidCurrency = errors.New("Invalid currency")
+var InvalidIncoterm = errors.New("Invalid incoterm")
+var InvalidExporter = errors.New("Invalid exporter")
+var InvalidImporter = errors.New("Invalid importer")
+var InvalidStateForCustoms = errors.New("Shipment is already shipped")
+
+type Party struct {
+	Name    string `json:"name" yaml:"name"`
+	Address string `json:"address" yaml:"address"`
+	Country string `json:"country" yaml:"country"`
+	TaxID   string `json:"tax_id,omitempty" yaml:"tax_id,omitempty"`
+}
+
+type CustomsItem struct {
+	HSCode      string `json:"hs_code" yaml:"hs_code"`
+	Description string `json:"description" yaml:"description"`
+	Quantity    int    `json:"quantity" yaml:"quantity"`
+	UnitValue   int64  `json:"unit_value" yaml:"unit_value"` // in minor units of the declaration currency
+}
+
+type CustomsDeclaration struct {
+	Items    []CustomsItem `json:"items" yaml:"items"`
+	Currency string        `json:"currency" yaml:"currency"`
+	Incoterm Incoterm      `json:"incoterm" yaml:"incoterm"`
+	Exporter Party         `json:"exporter" yaml:"exporter"`
+	Importer Party         `json:"importer" yaml:"importer"`
+}
+
+func (i Incoterm) IsValid() bool {
+	switch i {
+	case EXW, FCA, CPT, CIP, DAP, DPU, DDP, FAS, FOB, CFR, CIF:
+		return true
+	}
+
+	return false
+}
+
+func (p Party) isValid() bool {
+	return p.Name != "" && p.Address != "" && isUpper(p.Country, 2)
+}
+
+func (i CustomsItem) Validate() error {
+	code := strings.Replace(i.HSCode, ".", "", -1)
+	if len(code) < 6 || len(code) > 10 || strings.Trim(code, "0123456789") != "" {
+		return InvalidHSCode
+	}
+	if i.Description == "" {
+		return InvalidItemDescription
+	}
+	if i.Quantity < 1 {
+		return InvalidItemQuantity
+	}
+	if i.UnitValue < 0 {
+		return InvalidItemValue
+	}
+
+	return nil
+}
+
+func (d CustomsDeclaration) Validate() error {
+	if len(d.Items) == 0 {
+		return MissingCustomsItems
+	}
+	for _, i := range d.Items {
+		if err := i.Validate(); err != nil {
+			return err
+		}
+	}
+	if !isUpper(d.Currency, 3) {
+		return InvalidCurrency
+	}
+	if !d.Incoterm.IsValid() {
+		return InvalidIncoterm
+	}
+	if !d.Exporter.isValid() {
+		return InvalidExporter
+	}
+	if !d.Importer.isValid() {
+		return InvalidImporter
+	}
+
+	return nil
+}
+
+func (d CustomsDeclaration) TotalValue() int64 {
+	var total int64
+	for _, i := range d.Items {
+		total += int64(i.Quantity) * i.UnitValue
+	}
+
+	return total
+}
+
+func (s Shipment) IsInternational() bool {
+	return s.OriginCountry != "" && s.DestinationCountry != "" && s.OriginCountry != s.DestinationCountry
+}
+
+// DeclareCustoms attaches a declaration whose exporter and importer are in
+// the origin and destination countries of the shipment.
+func (s *Shipment) DeclareCustoms(d CustomsDeclaration) error {
+	if s.State != "" && s.State != Created && s.State != Handled {
+		return InvalidStateForCustoms
+	}
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	if d.Exporter.Country != s.OriginCountry {
+		return InvalidExporter
+	}
+	if d.Importer.Country != s.DestinationCountry {
+		return InvalidImporter
+	}
+
+	d.Items = append([]CustomsItem(nil), d.Items...)
+	s.Customs = &d
+
+	return nil
+}
+
+func (s Shipment) validateCustoms() error {
+	if !s.IsInternational() {
+		return nil
+	}
+	if s.Customs == nil {
+		return CustomsDeclarationRequired
+	}
+
+	return s.Customs.Validate()
+}
+
+func isUpper(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
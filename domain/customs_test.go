@@ -0,0 +1,168 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func validDeclaration() domain.CustomsDeclaration {
+	return domain.CustomsDeclaration{
+		Items: []domain.CustomsItem{
+			{HSCode: "6109.10", Description: "Cotton t-shirt", Quantity: 3, UnitValue: 1250},
+			{HSCode: "420221", Description: "Leather handbag", Quantity: 1, UnitValue: 8900},
+		},
+		Currency: "USD",
+		Incoterm: domain.DAP,
+		Exporter: domain.Party{Name: "Exporter SA", Address: "Av. Corrientes 1234, Buenos Aires", Country: "AR", TaxID: "30-12345678-9"},
+		Importer: domain.Party{Name: "Importer SL", Address: "Gran Via 1, Madrid", Country: "ES"},
+	}
+}
+
+func internationalShipment(state domain.ShipmentState) domain.Shipment {
+	return domain.Shipment{
+		ID:                 1,
+		State:              state,
+		Origin:             "Buenos Aires",
+		Destination:        "Madrid",
+		OriginCountry:      "AR",
+		DestinationCountry: "ES",
+	}
+}
+
+func TestCustomsDeclaration_Validate_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		change        func(*domain.CustomsDeclaration)
+		expectedError error
+	}{
+		{
+			name:          "Missing Items",
+			change:        func(d *domain.CustomsDeclaration) { d.Items = nil },
+			expectedError: domain.MissingCustomsItems,
+		},
+		{
+			name:          "Invalid HS Code",
+			change:        func(d *domain.CustomsDeclaration) { d.Items[0].HSCode = "61AB" },
+			expectedError: domain.InvalidHSCode,
+		},
+		{
+			name:          "Invalid Description",
+			change:        func(d *domain.CustomsDeclaration) { d.Items[0].Description = "" },
+			expectedError: domain.InvalidItemDescription,
+		},
+		{
+			name:          "Invalid Quantity",
+			change:        func(d *domain.CustomsDeclaration) { d.Items[1].Quantity = 0 },
+			expectedError: domain.InvalidItemQuantity,
+		},
+		{
+			name:          "Invalid Value",
+			change:        func(d *domain.CustomsDeclaration) { d.Items[1].UnitValue = -1 },
+			expectedError: domain.InvalidItemValue,
+		},
+		{
+			name:          "Invalid Currency",
+			change:        func(d *domain.CustomsDeclaration) { d.Currency = "usd" },
+			expectedError: domain.InvalidCurrency,
+		},
+		{
+			name:          "Invalid Incoterm",
+			change:        func(d *domain.CustomsDeclaration) { d.Incoterm = domain.Incoterm("XYZ") },
+			expectedError: domain.InvalidIncoterm,
+		},
+		{
+			name:          "Invalid Exporter",
+			change:        func(d *domain.CustomsDeclaration) { d.Exporter.Address = "" },
+			expectedError: domain.InvalidExporter,
+		},
+		{
+			name:          "Invalid Importer",
+			change:        func(d *domain.CustomsDeclaration) { d.Importer.Country = "Spain" },
+			expectedError: domain.InvalidImporter,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := validDeclaration()
+			c.change(&d)
+			assert.Equal(t, c.expectedError, d.Validate())
+		})
+	}
+}
+
+func TestCustomsDeclaration_TotalValue(t *testing.T) {
+	assert.Equal(t, int64(3*1250+8900), validDeclaration().TotalValue())
+}
+
+func TestShipment_IsInternational(t *testing.T) {
+	assert.True(t, internationalShipment(domain.Created).IsInternational())
+	assert.False(t, domain.Shipment{OriginCountry: "AR", DestinationCountry: "AR"}.IsInternational())
+	assert.False(t, domain.Shipment{}.IsInternational())
+}
+
+func TestShipment_DeclareCustoms_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		state         domain.ShipmentState
+		change        func(*domain.CustomsDeclaration)
+		expectedError error
+	}{
+		{
+			name:          "Invalid State Shipped",
+			state:         domain.Shipped,
+			change:        func(*domain.CustomsDeclaration) {},
+			expectedError: domain.InvalidStateForCustoms,
+		},
+		{
+			name:          "Invalid Declaration",
+			state:         domain.Created,
+			change:        func(d *domain.CustomsDeclaration) { d.Currency = "" },
+			expectedError: domain.InvalidCurrency,
+		},
+		{
+			name:          "Exporter Not In Origin Country",
+			state:         domain.Created,
+			change:        func(d *domain.CustomsDeclaration) { d.Exporter.Country = "UY" },
+			expectedError: domain.InvalidExporter,
+		},
+		{
+			name:          "Importer Not In Destination Country",
+			state:         domain.Created,
+			change:        func(d *domain.CustomsDeclaration) { d.Importer.Country = "FR" },
+			expectedError: domain.InvalidImporter,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := internationalShipment(c.state)
+			d := validDeclaration()
+			c.change(&d)
+			assert.Equal(t, c.expectedError, s.DeclareCustoms(d))
+			assert.Nil(t, s.Customs)
+		})
+	}
+}
+
+func TestShipment_Ship_CustomsDeclarationRequired(t *testing.T) {
+	s := internationalShipment(domain.Handled)
+
+	err := s.Ship("carrier", "TRK1")
+
+	assert.Equal(t, domain.CustomsDeclarationRequired, err)
+	assert.Equal(t, domain.Handled, s.State)
+}
+
+func TestShipment_Ship_International_OK(t *testing.T) {
+	s := internationalShipment(domain.Handled)
+
+	err := s.DeclareCustoms(validDeclaration())
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+
+	err = s.Ship("carrier", "TRK1")
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.Shipped, s.State)
+}
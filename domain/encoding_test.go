@@ -0,0 +1,133 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/facucachomeli/workshop-go-testing/internal/golden"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+// wireShipment fills every field so the golden files lock the name of each.
+func wireShipment() domain.Shipment {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	proof := shipmenttest.ValidProof()
+	proof.SignatureHash = "d2f1e4b3"
+	proof.PhotoRef = "photos/1.jpg"
+	declaration := validDeclaration()
+
+	return domain.Shipment{
+		ID:             2,
+		Tenant:         "acme",
+		Version:        4,
+		State:          domain.Cancelled,
+		Origin:         "Buenos Aires",
+		Destination:    "Madrid",
+		ReturnOf:       1,
+		Carrier:        "fake",
+		TrackingNumber: "TRK00000002",
+		Attempts:       []domain.DeliveryAttempt{{Reason: domain.RecipientAbsent, At: at}},
+		Proof:          &proof,
+		Legs: []domain.Leg{{
+			From:             "Buenos Aires",
+			To:               "Madrid",
+			Carrier:          "fake",
+			Mode:             domain.Air,
+			PlannedDeparture: at,
+			PlannedArrival:   at.Add(14 * time.Hour),
+			ActualDeparture:  at.Add(time.Hour),
+			ActualArrival:    at.Add(15 * time.Hour),
+		}},
+		Parcels: []domain.Parcel{{
+			Weight:         1500,
+			DangerousGoods: []domain.DangerousGood{{UNNumber: "UN3481", Class: 9, Description: "Lithium ion batteries packed with equipment"}},
+		}},
+		OriginCountry:      "AR",
+		DestinationCountry: "ES",
+		Customs:            &declaration,
+		Hold:               &domain.Hold{Reason: "address check", By: "support", At: at},
+		Cancellation: &domain.Cancellation{
+			Reason:    domain.CustomerRequest,
+			FromState: domain.Handled,
+			Fee:       500,
+			Currency:  "USD",
+			At:        at,
+		},
+		ServiceLevel: domain.Express,
+		CreatedAt:    at,
+		PromisedBy:   at.Add(24 * time.Hour),
+	}
+}
+
+func TestShipment_JSON(t *testing.T) {
+	data, err := json.MarshalIndent(wireShipment(), "", "  ")
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	golden.Assert(t, "shipment.json", append(data, '\n'))
+
+	var decoded domain.Shipment
+	err = json.Unmarshal(data, &decoded)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, wireShipment(), decoded)
+}
+
+func TestShipment_YAML(t *testing.T) {
+	data, err := yaml.Marshal(wireShipment())
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	golden.Assert(t, "shipment.yaml", data)
+
+	var decoded domain.Shipment
+	err = yaml.Unmarshal(data, &decoded)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, wireShipment(), decoded)
+}
+
+func TestShipment_JSON_Minimal(t *testing.T) {
+	s, _ := domain.NewShipment(1, "valid origin", "valid destination")
+
+	data, err := json.Marshal(s)
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, `{"id":1,"state":"","origin":"valid origin","destination":"valid destination","created_at":"0001-01-01T00:00:00Z","promised_by":"0001-01-01T00:00:00Z"}`, string(data))
+}
+
+func TestShipmentState_UnmarshalText_InvalidState(t *testing.T) {
+	var s domain.Shipment
+
+	err := json.Unmarshal([]byte(`{"id":1,"state":"Lost"}`), &s)
+	assert.Equal(t, domain.InvalidState, err)
+
+	err = yaml.Unmarshal([]byte("id: 1\nstate: Lost\n"), &s)
+	assert.Equal(t, domain.InvalidState, err)
+}
+
+func TestShipmentState_MarshalText_InvalidState(t *testing.T) {
+	_, err := json.Marshal(domain.Shipment{ID: 1, State: domain.ShipmentState("Lost")})
+	assert.True(t, errors.Is(err, domain.InvalidState), "expected '%s' error but got '%v'", domain.InvalidState, err)
+}
+
+func TestShipmentState_Text_RoundTrip(t *testing.T) {
+	states := []domain.ShipmentState{
+		"",
+		domain.Created,
+		domain.Handled,
+		domain.Shipped,
+		domain.Cancelled,
+		domain.Delivered,
+		domain.DeliveryFailed,
+		domain.Returned,
+	}
+
+	for _, state := range states {
+		text, err := state.MarshalText()
+		assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+
+		var decoded domain.ShipmentState
+		assert.Nil(t, decoded.UnmarshalText(text))
+		assert.Equal(t, state, decoded)
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ShipmentOnHold = errors.New("Shipment is on hold")
+var ShipmentNotOnHold = errors.New("Shipment is not on hold")
+var ShipmentAlreadyOnHold = errors.New("Shipment is already on hold")
+var InvalidHold = errors.New("Invalid hold")
+
+type Hold struct {
+	Reason string    `json:"reason" yaml:"reason"`
+	By     string    `json:"by" yaml:"by"`
+	At     time.Time `json:"at" yaml:"at"`
+}
+
+func (s Shipment) IsOnHold() bool {
+	return s.Hold != nil
+}
+
+// PlaceHold freezes the shipment: every state transition fails with
+// ShipmentOnHold until Release is called.
+func (s *Shipment) PlaceHold(reason string, by string, at time.Time) error {
+	if reason == "" || by == "" || at.IsZero() {
+		return InvalidHold
+	}
+	if s.IsOnHold() {
+		return ShipmentAlreadyOnHold
+	}
+
+	s.Hold = &Hold{reason, by, at}
+
+	return nil
+}
+
+func (s *Shipment) Release() error {
+	if !s.IsOnHold() {
+		return ShipmentNotOnHold
+	}
+
+	s.Hold = nil
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/stretchr/testify/assert"
+)
+
+var holdAt = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+
+func TestShipment_PlaceHold_Error(t *testing.T) {
+	s := domain.Shipment{State: domain.Created}
+
+	assert.Equal(t, domain.InvalidHold, s.PlaceHold("", "compliance", holdAt))
+	assert.Equal(t, domain.InvalidHold, s.PlaceHold("sanctions check", "", holdAt))
+	assert.Equal(t, domain.InvalidHold, s.PlaceHold("sanctions check", "compliance", time.Time{}))
+	assert.False(t, s.IsOnHold())
+
+	err := s.PlaceHold("sanctions check", "compliance", holdAt)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.ShipmentAlreadyOnHold, s.PlaceHold("fraud", "fraud team", holdAt))
+	assert.Equal(t, "sanctions check", s.Hold.Reason)
+}
+
+func TestShipment_Release_ShipmentNotOnHold(t *testing.T) {
+	s := domain.Shipment{State: domain.Created}
+
+	assert.Equal(t, domain.ShipmentNotOnHold, s.Release())
+}
+
+func TestShipment_OnHold_BlocksTransitions(t *testing.T) {
+	cases := []struct {
+		name       string
+		state      domain.ShipmentState
+		transition func(*domain.Shipment) error
+	}{
+		{
+			name:       "Create",
+			state:      "",
+			transition: func(s *domain.Shipment) error { return s.Create() },
+		},
+		{
+			name:       "Handle",
+			state:      domain.Created,
+			transition: func(s *domain.Shipment) error { return s.Handle() },
+		},
+		{
+			name:       "Ship",
+			state:      domain.Handled,
+			transition: func(s *domain.Shipment) error { return s.Ship("carrier", "TRK1") },
+		},
+		{
+			name:       "Deliver",
+			state:      domain.Shipped,
+			transition: func(s *domain.Shipment) error { return s.Deliver(shipmenttest.ValidProof()) },
+		},
+		{
+			name:       "Fail Delivery",
+			state:      domain.Shipped,
+			transition: func(s *domain.Shipment) error { return s.FailDelivery(domain.Refused, holdAt, 3) },
+		},
+		{
+			name:  "Return",
+			state: domain.Delivered,
+			transition: func(s *domain.Shipment) error {
+				_, err := s.Return(2)
+				return err
+			},
+		},
+		{
+			name:  "Cancel",
+			state: domain.Created,
+			transition: func(s *domain.Shipment) error {
+				return s.Cancel(domain.CustomerRequest, domain.DefaultFeeSchedule(), holdAt)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := shipmenttest.AShipment().InState(c.state).Build()
+			s.PlaceHold("sanctions check", "compliance", holdAt)
+
+			err := c.transition(&s)
+			assert.Equal(t, domain.ShipmentOnHold, err)
+			assert.Equal(t, c.state, s.State)
+
+			s.Release()
+			err = c.transition(&s)
+			assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+			assert.NotEqual(t, c.state, s.State)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type TransportMode string
+
+var Ground = TransportMode("Ground")
+var Air = TransportMode("Air")
+var Sea = TransportMode("Sea")
+
+var InvalidLeg = errors.New("Invalid leg")
+var InvalidLegIndex = errors.New("Invalid leg index")
+var LegDoesNotConnect = errors.New("Leg does not connect with the previous one")
+var LegsDoNotReachDestination = errors.New("Legs do not reach the destination")
+var LegsAlreadyStarted = errors.New("Legs already started")
+var LegAlreadyDeparted = errors.New("Leg already departed")
+var LegAlreadyArrived = errors.New("Leg already arrived")
+var LegNotDeparted = errors.New("Leg has not departed")
+var PreviousLegNotArrived = errors.New("Previous leg has not arrived")
+var LegsNotArrived = errors.New("Legs have not arrived at the destination")
+var InvalidStateForLeg = errors.New("Shipment state does not allow leg changes")
+
+type Leg struct {
+	From             string        `json:"from" yaml:"from"`
+	To               string        `json:"to" yaml:"to"`
+	Carrier          string        `json:"carrier,omitempty" yaml:"carrier,omitempty"`
+	Mode             TransportMode `json:"mode" yaml:"mode"`
+	PlannedDeparture time.Time     `json:"planned_departure" yaml:"planned_departure"`
+	PlannedArrival   time.Time     `json:"planned_arrival" yaml:"planned_arrival"`
+	ActualDeparture  time.Time     `json:"actual_departure" yaml:"actual_departure"`
+	ActualArrival    time.Time     `json:"actual_arrival" yaml:"actual_arrival"`
+}
+
+func (m TransportMode) IsValid() bool {
+	return m == Ground || m == Air || m == Sea
+}
+
+func (l Leg) Validate() error {
+	if l.From == "" || l.To == "" || l.From == l.To || l.Carrier == "" || !l.Mode.IsValid() {
+		return InvalidLeg
+	}
+	if l.PlannedArrival.Before(l.PlannedDeparture) {
+		return InvalidLeg
+	}
+
+	return nil
+}
+
+func (l Leg) HasDeparted() bool {
+	return !l.ActualDeparture.IsZero()
+}
+
+func (l Leg) HasArrived() bool {
+	return !l.ActualArrival.IsZero()
+}
+
+func (s *Shipment) AddLeg(l Leg) error {
+	if s.State != Created && s.State != Handled {
+		return InvalidStateForLeg
+	}
+	if s.hasDepartedLegs() {
+		return LegsAlreadyStarted
+	}
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	from := s.Origin
+	if len(s.Legs) > 0 {
+		from = s.Legs[len(s.Legs)-1].To
+	}
+	if l.From != from {
+		return LegDoesNotConnect
+	}
+
+	l.ActualDeparture = time.Time{}
+	l.ActualArrival = time.Time{}
+	s.Legs = append(s.Legs, l)
+
+	return nil
+}
+
+// DepartLeg starts leg i. Legs only depart once the shipment has been
+// shipped, which books the carrier and checks customs.
+func (s *Shipment) DepartLeg(i int, at time.Time) error {
+	if s.IsOnHold() {
+		return ShipmentOnHold
+	}
+	if i < 0 || i >= len(s.Legs) {
+		return InvalidLegIndex
+	}
+	if s.State != Shipped {
+		return InvalidStateForLeg
+	}
+	if s.Legs[len(s.Legs)-1].To != s.Destination {
+		return LegsDoNotReachDestination
+	}
+	if s.Legs[i].HasDeparted() {
+		return LegAlreadyDeparted
+	}
+	if i > 0 && !s.Legs[i-1].HasArrived() {
+		return PreviousLegNotArrived
+	}
+
+	s.Legs[i].ActualDeparture = at
+
+	return nil
+}
+
+// ArriveLeg ends leg i. Arriving the last leg leaves the shipment Shipped:
+// it is at the destination, and Deliver, which needs a proof, completes it.
+func (s *Shipment) ArriveLeg(i int, at time.Time) error {
+	if s.IsOnHold() {
+		return ShipmentOnHold
+	}
+	if i < 0 || i >= len(s.Legs) {
+		return InvalidLegIndex
+	}
+	if s.State != Shipped {
+		return InvalidStateForLeg
+	}
+	if !s.Legs[i].HasDeparted() {
+		return LegNotDeparted
+	}
+	if s.Legs[i].HasArrived() {
+		return LegAlreadyArrived
+	}
+
+	s.Legs[i].ActualArrival = at
+
+	return nil
+}
+
+// Location is where the shipment was last seen: the origin, or the
+// destination of the last leg that arrived. While a leg is in transit the
+// location is still its departure point.
+func (s Shipment) Location() string {
+	location := s.Origin
+	for _, l := range s.Legs {
+		if !l.HasArrived() {
+			break
+		}
+		location = l.To
+	}
+
+	return location
+}
+
+// hasArrived reports whether every leg, if any were planned, has arrived, so
+// the shipment is at its destination and can be handed to the recipient.
+func (s Shipment) hasArrived() bool {
+	for _, l := range s.Legs {
+		if !l.HasArrived() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s Shipment) hasDepartedLegs() bool {
+	for _, l := range s.Legs {
+		if l.HasDeparted() {
+			return true
+		}
+	}
+
+	return false
+}
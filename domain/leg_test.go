@@ -0,0 +1,244 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/stretchr/testify/assert"
+)
+
+var legStart = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+
+func plannedShipment() domain.Shipment {
+	return domain.Shipment{
+		ID:          1,
+		State:       domain.Shipped,
+		Origin:      "Buenos Aires",
+		Destination: "Madrid",
+		Legs: []domain.Leg{
+			{From: "Buenos Aires", To: "Sao Paulo", Carrier: "air carrier", Mode: domain.Air, PlannedDeparture: legStart, PlannedArrival: legStart.Add(3 * time.Hour)},
+			{From: "Sao Paulo", To: "Madrid", Carrier: "air carrier", Mode: domain.Air, PlannedDeparture: legStart.Add(5 * time.Hour), PlannedArrival: legStart.Add(15 * time.Hour)},
+		},
+	}
+}
+
+func TestShipment_AddLeg_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		state         domain.ShipmentState
+		leg           domain.Leg
+		expectedError error
+	}{
+		{
+			name:          "Invalid State Shipped",
+			state:         domain.Shipped,
+			leg:           domain.Leg{From: "Buenos Aires", To: "Madrid", Carrier: "carrier", Mode: domain.Air},
+			expectedError: domain.InvalidStateForLeg,
+		},
+		{
+			name:          "Invalid Mode",
+			state:         domain.Created,
+			leg:           domain.Leg{From: "Buenos Aires", To: "Madrid", Carrier: "carrier", Mode: domain.TransportMode("Rocket")},
+			expectedError: domain.InvalidLeg,
+		},
+		{
+			name:          "Missing Carrier",
+			state:         domain.Created,
+			leg:           domain.Leg{From: "Buenos Aires", To: "Madrid", Mode: domain.Air},
+			expectedError: domain.InvalidLeg,
+		},
+		{
+			name:          "Arrival Before Departure",
+			state:         domain.Created,
+			leg:           domain.Leg{From: "Buenos Aires", To: "Madrid", Carrier: "carrier", Mode: domain.Air, PlannedDeparture: legStart, PlannedArrival: legStart.Add(-time.Hour)},
+			expectedError: domain.InvalidLeg,
+		},
+		{
+			name:          "Does Not Connect",
+			state:         domain.Created,
+			leg:           domain.Leg{From: "Montevideo", To: "Madrid", Carrier: "carrier", Mode: domain.Air},
+			expectedError: domain.LegDoesNotConnect,
+		},
+	}
+
+	var s domain.Shipment
+	for _, c := range cases {
+		s = domain.Shipment{
+			State:       c.state,
+			Origin:      "Buenos Aires",
+			Destination: "Madrid",
+		}
+		t.Run(c.name, func(t *testing.T) {
+			err := s.AddLeg(c.leg)
+			assert.Equal(t, c.expectedError, err)
+			assert.Empty(t, s.Legs)
+		})
+	}
+}
+
+func TestShipment_AddLeg_OK(t *testing.T) {
+	s := domain.Shipment{
+		State:       domain.Created,
+		Origin:      "Buenos Aires",
+		Destination: "Madrid",
+	}
+	planned := plannedShipment()
+
+	for _, l := range planned.Legs {
+		err := s.AddLeg(l)
+		assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	}
+
+	assert.Equal(t, planned.Legs, s.Legs)
+	assert.Equal(t, domain.Created, s.State)
+}
+
+func TestShipment_AddLeg_LegsAlreadyStarted(t *testing.T) {
+	s := plannedShipment()
+	s.Destination = "Paris"
+	s.Legs[1].To = "Paris"
+	s.Legs[0].ActualDeparture = legStart
+	s.State = domain.Handled
+
+	err := s.AddLeg(domain.Leg{From: "Paris", To: "Berlin", Carrier: "carrier", Mode: domain.Ground})
+
+	assert.Equal(t, domain.LegsAlreadyStarted, err)
+	assert.Len(t, s.Legs, 2)
+}
+
+func TestShipment_DepartLeg_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		prepare       func(*domain.Shipment)
+		leg           int
+		expectedError error
+	}{
+		{
+			name:          "On Hold",
+			prepare:       func(s *domain.Shipment) { s.PlaceHold("sanctions check", "compliance", legStart) },
+			leg:           0,
+			expectedError: domain.ShipmentOnHold,
+		},
+		{
+			name:          "Invalid Index",
+			prepare:       func(*domain.Shipment) {},
+			leg:           2,
+			expectedError: domain.InvalidLegIndex,
+		},
+		{
+			name:          "Invalid State Created",
+			prepare:       func(s *domain.Shipment) { s.State = domain.Created },
+			leg:           0,
+			expectedError: domain.InvalidStateForLeg,
+		},
+		{
+			name:          "Not Shipped",
+			prepare:       func(s *domain.Shipment) { s.State = domain.Handled },
+			leg:           0,
+			expectedError: domain.InvalidStateForLeg,
+		},
+		{
+			name:          "Invalid State Delivered",
+			prepare:       func(s *domain.Shipment) { s.State = domain.Delivered },
+			leg:           0,
+			expectedError: domain.InvalidStateForLeg,
+		},
+		{
+			name:          "Does Not Reach Destination",
+			prepare:       func(s *domain.Shipment) { s.Destination = "Paris" },
+			leg:           0,
+			expectedError: domain.LegsDoNotReachDestination,
+		},
+		{
+			name:          "Previous Leg Not Arrived",
+			prepare:       func(*domain.Shipment) {},
+			leg:           1,
+			expectedError: domain.PreviousLegNotArrived,
+		},
+		{
+			name:          "Already Departed",
+			prepare:       func(s *domain.Shipment) { s.Legs[0].ActualDeparture = legStart },
+			leg:           0,
+			expectedError: domain.LegAlreadyDeparted,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := plannedShipment()
+			c.prepare(&s)
+			state := s.State
+			err := s.DepartLeg(c.leg, legStart)
+			assert.Equal(t, c.expectedError, err)
+			assert.Equal(t, state, s.State)
+		})
+	}
+}
+
+func TestShipment_ArriveLeg_Error(t *testing.T) {
+	s := plannedShipment()
+	s.State = domain.Handled
+
+	assert.Equal(t, domain.InvalidLegIndex, s.ArriveLeg(-1, legStart))
+	assert.Equal(t, domain.InvalidStateForLeg, s.ArriveLeg(0, legStart))
+
+	s.State = domain.Shipped
+	assert.Equal(t, domain.LegNotDeparted, s.ArriveLeg(0, legStart))
+
+	s.Legs[0].ActualDeparture = legStart
+	s.Legs[0].ActualArrival = legStart.Add(3 * time.Hour)
+	assert.Equal(t, domain.LegAlreadyArrived, s.ArriveLeg(0, legStart))
+}
+
+func TestShipment_Legs_OK(t *testing.T) {
+	s := plannedShipment()
+	assert.Equal(t, "Buenos Aires", s.Location())
+
+	err := s.DepartLeg(0, legStart)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.Shipped, s.State)
+	assert.Equal(t, "Buenos Aires", s.Location())
+
+	err = s.ArriveLeg(0, legStart.Add(3*time.Hour))
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "Sao Paulo", s.Location())
+
+	err = s.DepartLeg(1, legStart.Add(5*time.Hour))
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+
+	err = s.ArriveLeg(1, legStart.Add(16*time.Hour))
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "Madrid", s.Location())
+	assert.Equal(t, domain.Shipped, s.State)
+	assert.Equal(t, legStart.Add(16*time.Hour), s.Legs[1].ActualArrival)
+}
+
+func TestShipment_Deliver_LegsNotArrived(t *testing.T) {
+	s := plannedShipment()
+	s.DepartLeg(0, legStart)
+
+	assert.Equal(t, domain.LegsNotArrived, s.Deliver(shipmenttest.ValidProof()))
+	assert.Equal(t, domain.LegsNotArrived, s.FailDelivery(domain.RecipientAbsent, legStart, 3))
+
+	s.ArriveLeg(0, legStart.Add(3*time.Hour))
+	s.DepartLeg(1, legStart.Add(5*time.Hour))
+
+	assert.Equal(t, domain.LegsNotArrived, s.Deliver(shipmenttest.ValidProof()))
+	assert.Equal(t, domain.Shipped, s.State)
+	assert.Nil(t, s.Proof)
+}
+
+func TestShipment_Deliver_AfterLastLegArrived(t *testing.T) {
+	s := plannedShipment()
+	s.DepartLeg(0, legStart)
+	s.ArriveLeg(0, legStart.Add(3*time.Hour))
+	s.DepartLeg(1, legStart.Add(5*time.Hour))
+	s.ArriveLeg(1, legStart.Add(16*time.Hour))
+
+	err := s.Deliver(shipmenttest.ValidProof())
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.Delivered, s.State)
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+type HazardClass int
+
+var InvalidParcelWeight = errors.New("Invalid parcel weight")
+var InvalidUNNumber = errors.New("Invalid UN number")
+var InvalidHazardClass = errors.New("Invalid hazard class")
+
+type DangerousGood struct {
+	UNNumber    string      `json:"un_number" yaml:"un_number"`
+	Class       HazardClass `json:"class" yaml:"class"`
+	Description string      `json:"description" yaml:"description"`
+}
+
+type Parcel struct {
+	Weight         int             `json:"weight" yaml:"weight"` // in grams
+	DangerousGoods []DangerousGood `json:"dangerous_goods,omitempty" yaml:"dangerous_goods,omitempty"`
+}
+
+func (g DangerousGood) Validate() error {
+	if len(g.UNNumber) != 6 || !strings.HasPrefix(g.UNNumber, "UN") || strings.Trim(g.UNNumber[2:], "0123456789") != "" {
+		return InvalidUNNumber
+	}
+	if g.Class < 1 || g.Class > 9 {
+		return InvalidHazardClass
+	}
+
+	return nil
+}
+
+func (p Parcel) Validate() error {
+	if p.Weight <= 0 {
+		return InvalidParcelWeight
+	}
+	for _, g := range p.DangerousGoods {
+		if err := g.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TransportModes lists the modes used by the planned legs, or Ground when
+// no legs have been planned yet.
+func (s Shipment) TransportModes() []TransportMode {
+	var modes []TransportMode
+	seen := map[TransportMode]bool{}
+	for _, l := range s.Legs {
+		if !seen[l.Mode] {
+			seen[l.Mode] = true
+			modes = append(modes, l.Mode)
+		}
+	}
+	if len(modes) == 0 {
+		modes = append(modes, Ground)
+	}
+
+	return modes
+}
@@ -0,0 +1,58 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParcel_Validate_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		parcel        domain.Parcel
+		expectedError error
+	}{
+		{
+			name:          "Invalid Weight",
+			parcel:        domain.Parcel{Weight: 0},
+			expectedError: domain.InvalidParcelWeight,
+		},
+		{
+			name:          "Invalid UN Number",
+			parcel:        domain.Parcel{Weight: 500, DangerousGoods: []domain.DangerousGood{{UNNumber: "3480", Class: 9}}},
+			expectedError: domain.InvalidUNNumber,
+		},
+		{
+			name:          "Invalid Hazard Class",
+			parcel:        domain.Parcel{Weight: 500, DangerousGoods: []domain.DangerousGood{{UNNumber: "UN3480", Class: 10}}},
+			expectedError: domain.InvalidHazardClass,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expectedError, c.parcel.Validate())
+		})
+	}
+}
+
+func TestShipment_Create_InvalidParcel(t *testing.T) {
+	s := domain.Shipment{
+		Parcels: []domain.Parcel{{Weight: -1}},
+	}
+
+	err := s.Create()
+
+	assert.Equal(t, domain.InvalidParcelWeight, err)
+	assert.Zero(t, s.State)
+}
+
+func TestShipment_TransportModes(t *testing.T) {
+	assert.Equal(t, []domain.TransportMode{domain.Ground}, domain.Shipment{}.TransportModes())
+
+	s := domain.Shipment{
+		Legs: []domain.Leg{{Mode: domain.Ground}, {Mode: domain.Air}, {Mode: domain.Ground}},
+	}
+	assert.Equal(t, []domain.TransportMode{domain.Ground, domain.Air}, s.TransportModes())
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var InvalidRecipientName = errors.New("Invalid recipient name")
+var InvalidSignature = errors.New("Invalid signature")
+var InvalidCoordinates = errors.New("Invalid coordinates")
+var InvalidDeliveryTime = errors.New("Invalid delivery time")
+
+type ProofOfDelivery struct {
+	RecipientName string    `json:"recipient_name" yaml:"recipient_name"`
+	Signature     []byte    `json:"signature,omitempty" yaml:"signature,omitempty,flow"`
+	SignatureHash string    `json:"signature_hash,omitempty" yaml:"signature_hash,omitempty"`
+	PhotoRef      string    `json:"photo_ref,omitempty" yaml:"photo_ref,omitempty"`
+	Latitude      float64   `json:"latitude" yaml:"latitude"`
+	Longitude     float64   `json:"longitude" yaml:"longitude"`
+	DeliveredAt   time.Time `json:"delivered_at" yaml:"delivered_at"`
+}
+
+func (p ProofOfDelivery) Validate() error {
+	if p.RecipientName == "" {
+		return InvalidRecipientName
+	}
+	if len(p.Signature) == 0 && p.SignatureHash == "" {
+		return InvalidSignature
+	}
+	if len(p.Signature) > 0 && p.SignatureHash != "" && p.SignatureHash != hashSignature(p.Signature) {
+		return InvalidSignature
+	}
+	if p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+		return InvalidCoordinates
+	}
+	if p.DeliveredAt.IsZero() {
+		return InvalidDeliveryTime
+	}
+
+	return nil
+}
+
+func hashSignature(signature []byte) string {
+	sum := sha256.Sum256(signature)
+	return hex.EncodeToString(sum[:])
+}
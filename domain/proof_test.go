@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofOfDelivery_Validate_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		change        func(*domain.ProofOfDelivery)
+		expectedError error
+	}{
+		{
+			name:          "Invalid Recipient Name",
+			change:        func(p *domain.ProofOfDelivery) { p.RecipientName = "" },
+			expectedError: domain.InvalidRecipientName,
+		},
+		{
+			name: "Missing Signature",
+			change: func(p *domain.ProofOfDelivery) {
+				p.Signature = nil
+				p.SignatureHash = ""
+			},
+			expectedError: domain.InvalidSignature,
+		},
+		{
+			name:          "Signature Hash Mismatch",
+			change:        func(p *domain.ProofOfDelivery) { p.SignatureHash = "deadbeef" },
+			expectedError: domain.InvalidSignature,
+		},
+		{
+			name:          "Invalid Latitude",
+			change:        func(p *domain.ProofOfDelivery) { p.Latitude = 91 },
+			expectedError: domain.InvalidCoordinates,
+		},
+		{
+			name:          "Invalid Longitude",
+			change:        func(p *domain.ProofOfDelivery) { p.Longitude = -181 },
+			expectedError: domain.InvalidCoordinates,
+		},
+		{
+			name:          "Invalid Delivery Time",
+			change:        func(p *domain.ProofOfDelivery) { p.DeliveredAt = time.Time{} },
+			expectedError: domain.InvalidDeliveryTime,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := shipmenttest.ValidProof()
+			c.change(&p)
+			assert.Equal(t, c.expectedError, p.Validate())
+		})
+	}
+}
+
+func TestProofOfDelivery_Validate_OK(t *testing.T) {
+	sum := sha256.Sum256([]byte("signature image"))
+	hash := hex.EncodeToString(sum[:])
+
+	withBoth := shipmenttest.ValidProof()
+	withBoth.SignatureHash = hash
+	hashOnly := shipmenttest.ValidProof()
+	hashOnly.Signature = nil
+	hashOnly.SignatureHash = hash
+
+	for _, p := range []domain.ProofOfDelivery{shipmenttest.ValidProof(), withBoth, hashOnly} {
+		err := p.Validate()
+		assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	}
+}
@@ -1,16 +1,40 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type Shipment struct {
-	ID          ShipmentID
-	State       ShipmentState
-	Origin      string
-	Destination string
+	ID             ShipmentID        `json:"id" yaml:"id"`
+	Tenant         TenantID          `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+	Version        int               `json:"version,omitempty" yaml:"version,omitempty"`
+	State          ShipmentState     `json:"state" yaml:"state"`
+	Origin         string            `json:"origin" yaml:"origin"`
+	Destination    string            `json:"destination" yaml:"destination"`
+	ReturnOf       ShipmentID        `json:"return_of,omitempty" yaml:"return_of,omitempty"`
+	Carrier        string            `json:"carrier,omitempty" yaml:"carrier,omitempty"`
+	TrackingNumber string            `json:"tracking_number,omitempty" yaml:"tracking_number,omitempty"`
+	Attempts       []DeliveryAttempt `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	Proof          *ProofOfDelivery  `json:"proof,omitempty" yaml:"proof,omitempty"`
+	Legs           []Leg             `json:"legs,omitempty" yaml:"legs,omitempty"`
+	Parcels        []Parcel          `json:"parcels,omitempty" yaml:"parcels,omitempty"`
+
+	OriginCountry      string              `json:"origin_country,omitempty" yaml:"origin_country,omitempty"`
+	DestinationCountry string              `json:"destination_country,omitempty" yaml:"destination_country,omitempty"`
+	Customs            *CustomsDeclaration `json:"customs,omitempty" yaml:"customs,omitempty"`
+	Hold               *Hold               `json:"hold,omitempty" yaml:"hold,omitempty"`
+	Cancellation       *Cancellation       `json:"cancellation,omitempty" yaml:"cancellation,omitempty"`
+
+	ServiceLevel ServiceLevel `json:"service_level,omitempty" yaml:"service_level,omitempty"`
+	CreatedAt    time.Time    `json:"created_at" yaml:"created_at"`
+	PromisedBy   time.Time    `json:"promised_by" yaml:"promised_by"`
 }
 
 type ShipmentID int
 
+type TenantID string
+
 type ShipmentState string
 
 var Created = ShipmentState("Created")
@@ -18,13 +42,54 @@ var Handled = ShipmentState("Handled")
 var Shipped = ShipmentState("Shipped")
 var Cancelled = ShipmentState("Cancelled")
 var Delivered = ShipmentState("Delivered")
+var DeliveryFailed = ShipmentState("DeliveryFailed")
+var Returned = ShipmentState("Returned")
+
+var states = []ShipmentState{Created, Handled, Shipped, Cancelled, Delivered, DeliveryFailed, Returned}
 
 var InvalidOrigin = errors.New("Invalid Origin")
 var InvalidDestination = errors.New("Invalid Destination")
 var InvalidState = errors.New("Invalid State")
 var ShipmentAlreadyCreated = errors.New("Shipment already has a state")
+var InvalidStateForHandle = errors.New("Shipment is not created")
+var InvalidStateForShip = errors.New("Shipment is not handled")
+var InvalidTrackingNumber = errors.New("Invalid tracking number")
 var InvalidStateForDeliver = errors.New("Shipment is not shipped")
 var ShipmentAlreadyDelivered = errors.New("Shipment is already delivered")
+var InvalidStateForReturn = errors.New("Shipment is not delivered nor failed delivery")
+var ShipmentAlreadyReturned = errors.New("Shipment is already returned")
+
+func (s ShipmentState) IsValid() bool {
+	for _, state := range states {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalText and UnmarshalText let encoders see the state as a plain
+// string while refusing to read or write unknown states. The empty state of
+// a shipment that was never created is allowed.
+func (s ShipmentState) MarshalText() ([]byte, error) {
+	if s != "" && !s.IsValid() {
+		return nil, InvalidState
+	}
+
+	return []byte(s), nil
+}
+
+func (s *ShipmentState) UnmarshalText(text []byte) error {
+	state := ShipmentState(text)
+	if state != "" && !state.IsValid() {
+		return InvalidState
+	}
+
+	*s = state
+
+	return nil
+}
 
 func NewShipment(id ShipmentID, origin string, destination string) (Shipment, error) {
 	if origin == "" {
@@ -35,39 +100,179 @@ func NewShipment(id ShipmentID, origin string, destination string) (Shipment, er
 	}
 
 	return Shipment{
-		id,
-		"",
-		origin,
-		destination,
+		ID:          id,
+		Origin:      origin,
+		Destination: destination,
 	}, nil
 }
 
 func (s *Shipment) Create() error {
+	if s.IsOnHold() {
+		return ShipmentOnHold
+	}
 	if s.State != "" {
 		return ShipmentAlreadyCreated
 	}
+	for _, p := range s.Parcels {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
 
 	s.State = Created
 
 	return nil
 }
 
-func (s *Shipment) Deliver() error {
+func (s *Shipment) Handle() error {
+	if s.IsOnHold() {
+		return ShipmentOnHold
+	}
+	if s.State != Created {
+		return InvalidStateForHandle
+	}
+
+	s.State = Handled
+
+	return nil
+}
+
+func (s *Shipment) CanShip() error {
+	if s.IsOnHold() {
+		return ShipmentOnHold
+	}
+	if s.State != Handled {
+		return InvalidStateForShip
+	}
+
+	return s.validateCustoms()
+}
+
+func (s *Shipment) Ship(carrier string, tracking string) error {
+	if err := s.CanShip(); err != nil {
+		return err
+	}
+	if tracking == "" {
+		return InvalidTrackingNumber
+	}
+
+	s.Carrier = carrier
+	s.TrackingNumber = tracking
+	s.State = Shipped
+
+	return nil
+}
+
+func (s *Shipment) Deliver(p ProofOfDelivery) error {
+	if s.IsOnHold() {
+		return ShipmentOnHold
+	}
 	if s.State == Delivered {
 		return ShipmentAlreadyDelivered
 	}
 	if s.State != Shipped {
 		return InvalidStateForDeliver
 	}
+	if !s.hasArrived() {
+		return LegsNotArrived
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
 
+	if p.SignatureHash == "" {
+		p.SignatureHash = hashSignature(p.Signature)
+	}
+	s.Proof = &p
 	s.State = Delivered
 
 	return nil
 }
 
+func (s *Shipment) Return(id ShipmentID) (Shipment, error) {
+	if s.IsOnHold() {
+		return Shipment{}, ShipmentOnHold
+	}
+	if s.State == Returned {
+		return Shipment{}, ShipmentAlreadyReturned
+	}
+	if s.State != Delivered && s.State != DeliveryFailed {
+		return Shipment{}, InvalidStateForReturn
+	}
+
+	r, err := NewShipment(id, s.Destination, s.Origin)
+	if err != nil {
+		return Shipment{}, err
+	}
+	r.ReturnOf = s.ID
+	r.Tenant = s.Tenant
+	r.ServiceLevel = s.ServiceLevel
+	r.OriginCountry = s.DestinationCountry
+	r.DestinationCountry = s.OriginCountry
+	if err := r.Create(); err != nil {
+		return Shipment{}, err
+	}
+
+	s.State = Returned
+
+	return r, nil
+}
+
 func (s *Shipment) IsNil() bool {
 	return s.ID == 0 &&
+		s.Tenant == "" &&
+		s.Version == 0 &&
 		s.State == "" &&
 		s.Origin == "" &&
-		s.Destination == ""
+		s.Destination == "" &&
+		s.ReturnOf == 0 &&
+		s.Carrier == "" &&
+		s.TrackingNumber == "" &&
+		len(s.Attempts) == 0 &&
+		s.Proof == nil &&
+		len(s.Legs) == 0 &&
+		len(s.Parcels) == 0 &&
+		s.OriginCountry == "" &&
+		s.DestinationCountry == "" &&
+		s.Customs == nil &&
+		s.Hold == nil &&
+		s.Cancellation == nil &&
+		s.ServiceLevel == "" &&
+		s.CreatedAt.IsZero() &&
+		s.PromisedBy.IsZero()
+}
+
+// Clone returns a copy that shares no slices or pointers with s, so changes
+// to one never show through the other.
+func (s Shipment) Clone() Shipment {
+	s.Attempts = append([]DeliveryAttempt(nil), s.Attempts...)
+	s.Legs = append([]Leg(nil), s.Legs...)
+	if s.Parcels != nil {
+		parcels := make([]Parcel, len(s.Parcels))
+		for i, p := range s.Parcels {
+			p.DangerousGoods = append([]DangerousGood(nil), p.DangerousGoods...)
+			parcels[i] = p
+		}
+		s.Parcels = parcels
+	}
+	if s.Proof != nil {
+		p := *s.Proof
+		p.Signature = append([]byte(nil), p.Signature...)
+		s.Proof = &p
+	}
+	if s.Customs != nil {
+		c := *s.Customs
+		c.Items = append([]CustomsItem(nil), c.Items...)
+		s.Customs = &c
+	}
+	if s.Hold != nil {
+		h := *s.Hold
+		s.Hold = &h
+	}
+	if s.Cancellation != nil {
+		c := *s.Cancellation
+		s.Cancellation = &c
+	}
+
+	return s
 }
@@ -2,8 +2,10 @@ package domain_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -107,6 +109,107 @@ func TestShipment_Create_OK(t *testing.T) {
 	assert.Equal(t, domain.Created, s.State)
 }
 
+func TestShipment_Handle_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		state         domain.ShipmentState
+		expectedError error
+	}{
+		{
+			name:          "Invalid State Empty",
+			state:         "",
+			expectedError: domain.InvalidStateForHandle,
+		},
+		{
+			name:          "Invalid State Handled",
+			state:         domain.Handled,
+			expectedError: domain.InvalidStateForHandle,
+		},
+		{
+			name:          "Invalid State Cancelled",
+			state:         domain.Cancelled,
+			expectedError: domain.InvalidStateForHandle,
+		},
+	}
+
+	var s domain.Shipment
+	for _, c := range cases {
+		s = domain.Shipment{
+			State: c.state,
+		}
+		t.Run(c.name, func(t *testing.T) {
+			err := s.Handle()
+			assert.Equal(t, c.state, s.State)
+			assert.NotNilf(t, err, "expected error but found none")
+			assert.Equal(t, c.expectedError, err)
+		})
+	}
+}
+
+func TestShipment_Handle_OK(t *testing.T) {
+	s := domain.Shipment{
+		State: domain.Created,
+	}
+	err := s.Handle()
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.Handled, s.State)
+}
+
+func TestShipment_Ship_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		state         domain.ShipmentState
+		tracking      string
+		expectedError error
+	}{
+		{
+			name:          "Invalid State Created",
+			state:         domain.Created,
+			tracking:      "TRK1",
+			expectedError: domain.InvalidStateForShip,
+		},
+		{
+			name:          "Invalid State Shipped",
+			state:         domain.Shipped,
+			tracking:      "TRK1",
+			expectedError: domain.InvalidStateForShip,
+		},
+		{
+			name:          "Invalid Tracking Number",
+			state:         domain.Handled,
+			tracking:      "",
+			expectedError: domain.InvalidTrackingNumber,
+		},
+	}
+
+	var s domain.Shipment
+	for _, c := range cases {
+		s = domain.Shipment{
+			State: c.state,
+		}
+		t.Run(c.name, func(t *testing.T) {
+			err := s.Ship("carrier", c.tracking)
+			assert.Equal(t, c.state, s.State)
+			assert.Empty(t, s.TrackingNumber)
+			assert.NotNilf(t, err, "expected error but found none")
+			assert.Equal(t, c.expectedError, err)
+		})
+	}
+}
+
+func TestShipment_Ship_OK(t *testing.T) {
+	s := domain.Shipment{
+		State: domain.Handled,
+	}
+	err := s.Ship("carrier", "TRK1")
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.Shipped, s.State)
+	assert.Equal(t, "carrier", s.Carrier)
+	assert.Equal(t, "TRK1", s.TrackingNumber)
+}
+
 func TestShipment_Deliver_Error(t *testing.T) {
 	cases := []struct {
 		name          string
@@ -136,7 +239,7 @@ func TestShipment_Deliver_Error(t *testing.T) {
 			State: c.state,
 		}
 		t.Run(c.name, func(t *testing.T) {
-			err := s.Deliver()
+			err := s.Deliver(shipmenttest.ValidProof())
 			assert.Equal(t, c.state, s.State)
 			assert.NotNilf(t, err, "expected error but found none")
 			assert.Equal(t, c.expectedError, err)
@@ -148,7 +251,134 @@ func TestShipment_Deliver_OK(t *testing.T) {
 	s := domain.Shipment{
 		State: domain.Shipped,
 	}
-	err := s.Deliver()
+	err := s.Deliver(shipmenttest.ValidProof())
 	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
 	assert.Equal(t, domain.Delivered, s.State)
+	if assert.NotNil(t, s.Proof) {
+		assert.Equal(t, "Jane Doe", s.Proof.RecipientName)
+		assert.NotEmpty(t, s.Proof.SignatureHash)
+	}
+}
+
+func TestShipment_Deliver_InvalidProof(t *testing.T) {
+	p := shipmenttest.ValidProof()
+	p.RecipientName = ""
+	s := domain.Shipment{
+		State: domain.Shipped,
+	}
+	err := s.Deliver(p)
+	assert.Equal(t, domain.InvalidRecipientName, err)
+	assert.Equal(t, domain.Shipped, s.State)
+	assert.Nil(t, s.Proof)
+}
+
+func TestShipment_Return_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		state         domain.ShipmentState
+		expectedError error
+	}{
+		{
+			name:          "Invalid State Created",
+			state:         domain.Created,
+			expectedError: domain.InvalidStateForReturn,
+		},
+		{
+			name:          "Invalid State Shipped",
+			state:         domain.Shipped,
+			expectedError: domain.InvalidStateForReturn,
+		},
+		{
+			name:          "Invalid State Cancelled",
+			state:         domain.Cancelled,
+			expectedError: domain.InvalidStateForReturn,
+		},
+		{
+			name:          "Invalid State Returned",
+			state:         domain.Returned,
+			expectedError: domain.ShipmentAlreadyReturned,
+		},
+	}
+
+	var s domain.Shipment
+	for _, c := range cases {
+		s = shipmenttest.AShipment().InState(c.state).Build()
+		t.Run(c.name, func(t *testing.T) {
+			r, err := s.Return(2)
+			assert.Equal(t, c.state, s.State)
+			assert.NotNilf(t, err, "expected error but found none")
+			assert.Equal(t, c.expectedError, err)
+			assert.True(t, r.IsNil(), "expected return shipment to be nil but got %#v", r)
+		})
+	}
+}
+
+func TestShipment_Return_OK(t *testing.T) {
+	for _, state := range []domain.ShipmentState{domain.Delivered, domain.DeliveryFailed} {
+		t.Run(string(state), func(t *testing.T) {
+			s := domain.Shipment{
+				ID:          1,
+				Tenant:      "merchant",
+				State:       state,
+				Origin:      "valid origin",
+				Destination: "valid destination",
+			}
+			r, err := s.Return(2)
+
+			assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+			assert.Equal(t, domain.Returned, s.State)
+			assert.Equal(t, domain.ShipmentID(2), r.ID)
+			assert.Equal(t, domain.TenantID("merchant"), r.Tenant)
+			assert.Equal(t, domain.ShipmentID(1), r.ReturnOf)
+			assert.Equal(t, domain.Created, r.State)
+			assert.Equal(t, "valid destination", r.Origin)
+			assert.Equal(t, "valid origin", r.Destination)
+		})
+	}
+}
+
+func FuzzNewShipment(f *testing.F) {
+	f.Add(1, "", "")
+	f.Add(1, "valid origin", "")
+	f.Add(1, "valid origin", "valid destination")
+	f.Add(0, "Buenos Aires", "Madrid")
+
+	f.Fuzz(func(t *testing.T, id int, origin string, destination string) {
+		s, err := domain.NewShipment(domain.ShipmentID(id), origin, destination)
+		if origin == "" || destination == "" {
+			assert.NotNilf(t, err, "expected error but found none")
+			assert.True(t, s.IsNil(), "expected shipment to be nil but got %#v", s)
+			return
+		}
+
+		assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+		assert.Equal(t, domain.ShipmentID(id), s.ID)
+		assert.Equal(t, origin, s.Origin)
+		assert.Equal(t, destination, s.Destination)
+		assert.Nil(t, s.Create())
+		assert.Equal(t, domain.Created, s.State)
+	})
+}
+
+func TestShipment_Clone(t *testing.T) {
+	s := wireShipment()
+
+	c := s.Clone()
+	assert.Equal(t, s, c)
+
+	c.Attempts[0].Reason = domain.Refused
+	c.Proof.Signature[0] = 'x'
+	c.Legs[0].ActualArrival = time.Time{}
+	c.Parcels[0].DangerousGoods[0].Class = 3
+	c.Customs.Items[0].Quantity = 99
+	c.Hold.Reason = "changed"
+	c.Cancellation.Fee = 0
+
+	assert.Equal(t, wireShipment(), s)
+}
+
+func TestShipment_Clone_Empty(t *testing.T) {
+	s, _ := domain.NewShipment(1, "valid origin", "valid destination")
+
+	assert.Equal(t, s, s.Clone())
 }
@@ -0,0 +1,228 @@
+// Package shipmenttest builds domain.Shipment fixtures for tests, so adding a
+// field to the shipment does not break every test that needs one.
+package shipmenttest
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+var Epoch = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+
+type Builder struct {
+	s domain.Shipment
+}
+
+// AShipment starts from the fixture most tests share: shipment 1 created
+// from "valid origin" to "valid destination".
+func AShipment() *Builder {
+	return &Builder{domain.Shipment{
+		ID:          1,
+		State:       domain.Created,
+		Origin:      "valid origin",
+		Destination: "valid destination",
+	}}
+}
+
+func (b *Builder) WithID(id domain.ShipmentID) *Builder {
+	b.s.ID = id
+	return b
+}
+
+func (b *Builder) ForTenant(tenant domain.TenantID) *Builder {
+	b.s.Tenant = tenant
+	return b
+}
+
+// InState sets the state directly, skipping the transitions. Use BringTo
+// when the fields a transition fills in matter to the test.
+func (b *Builder) InState(state domain.ShipmentState) *Builder {
+	b.s.State = state
+	return b
+}
+
+func (b *Builder) From(origin string) *Builder {
+	b.s.Origin = origin
+	return b
+}
+
+func (b *Builder) To(destination string) *Builder {
+	b.s.Destination = destination
+	return b
+}
+
+func (b *Builder) Between(originCountry string, destinationCountry string) *Builder {
+	b.s.OriginCountry = originCountry
+	b.s.DestinationCountry = destinationCountry
+	return b
+}
+
+func (b *Builder) WithServiceLevel(level domain.ServiceLevel) *Builder {
+	b.s.ServiceLevel = level
+	return b
+}
+
+func (b *Builder) WithParcels(parcels ...domain.Parcel) *Builder {
+	b.s.Parcels = parcels
+	return b
+}
+
+func (b *Builder) WithCustoms(d domain.CustomsDeclaration) *Builder {
+	b.s.Customs = &d
+	return b
+}
+
+func (b *Builder) ShippedWith(carrier string, tracking string) *Builder {
+	b.s.Carrier = carrier
+	b.s.TrackingNumber = tracking
+	return b
+}
+
+func (b *Builder) OnHold(reason string, by string) *Builder {
+	b.s.Hold = &domain.Hold{Reason: reason, By: by, At: Epoch}
+	return b
+}
+
+func (b *Builder) Build() domain.Shipment {
+	s := b.s
+	s.Parcels = append([]domain.Parcel(nil), b.s.Parcels...)
+	if len(s.Parcels) == 0 {
+		s.Parcels = nil
+	}
+
+	return s
+}
+
+var origins = []string{"Buenos Aires", "Córdoba", "Rosario", "Mendoza"}
+var destinations = []string{"Madrid", "Montevideo", "Santiago", "São Paulo"}
+var levels = []domain.ServiceLevel{domain.Express, domain.Standard, domain.Economy}
+
+// Random returns a builder for a valid created shipment whose fields are
+// drawn from seed. The same seed always yields the same shipment, so failures
+// can be replayed by logging it.
+func Random(seed int64) *Builder {
+	r := rand.New(rand.NewSource(seed))
+
+	parcels := make([]domain.Parcel, 1+r.Intn(3))
+	for i := range parcels {
+		parcels[i] = domain.Parcel{Weight: 1 + r.Intn(30000)}
+	}
+
+	return AShipment().
+		WithID(domain.ShipmentID(1 + r.Intn(1000000))).
+		From(origins[r.Intn(len(origins))]).
+		To(destinations[r.Intn(len(destinations))]).
+		WithServiceLevel(levels[r.Intn(len(levels))]).
+		WithParcels(parcels...)
+}
+
+func ValidProof() domain.ProofOfDelivery {
+	return domain.ProofOfDelivery{
+		RecipientName: "Jane Doe",
+		Signature:     []byte("signature image"),
+		Latitude:      -34.6037,
+		Longitude:     -58.3816,
+		DeliveredAt:   Epoch,
+	}
+}
+
+// BringTo drives s from its current state to state through the legal
+// transitions only, failing the test if any of them is rejected. When more
+// than one route leads to state, the first one passing through the current
+// state is taken.
+func BringTo(t testing.TB, s domain.Shipment, state domain.ShipmentState) domain.Shipment {
+	t.Helper()
+
+	routes, ok := paths[state]
+	if !ok {
+		t.Fatalf("no transitions lead to state '%s'", state)
+	}
+
+	var path []step
+	for _, route := range routes {
+		if start := startOf(route, s.State); start >= 0 {
+			path = route[start:]
+			break
+		}
+	}
+	if path == nil {
+		t.Fatalf("no transitions lead from '%s' to '%s'", s.State, state)
+	}
+
+	for _, step := range path {
+		if err := step.apply(&s); err != nil {
+			t.Fatalf("could not bring shipment %d from '%s' to '%s': %s", s.ID, s.State, step.to, err)
+		}
+	}
+
+	return s
+}
+
+// startOf returns the index of the first step of route to apply to a
+// shipment in state, or -1 if route does not pass through it.
+func startOf(route []step, state domain.ShipmentState) int {
+	if state == "" {
+		return 0
+	}
+	for i, step := range route {
+		if step.to == state {
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+type step struct {
+	to    domain.ShipmentState
+	apply func(*domain.Shipment) error
+}
+
+var create = step{domain.Created, func(s *domain.Shipment) error {
+	return s.Create()
+}}
+
+var handle = step{domain.Handled, func(s *domain.Shipment) error {
+	return s.Handle()
+}}
+
+var ship = step{domain.Shipped, func(s *domain.Shipment) error {
+	return s.Ship("fake", fmt.Sprintf("TRK%08d", s.ID))
+}}
+
+var deliver = step{domain.Delivered, func(s *domain.Shipment) error {
+	return s.Deliver(ValidProof())
+}}
+
+var failDelivery = step{domain.DeliveryFailed, func(s *domain.Shipment) error {
+	return s.FailDelivery(domain.Refused, Epoch, 1)
+}}
+
+var cancel = step{domain.Cancelled, func(s *domain.Shipment) error {
+	return s.Cancel(domain.CustomerRequest, domain.DefaultFeeSchedule(), Epoch)
+}}
+
+var giveBack = step{domain.Returned, func(s *domain.Shipment) error {
+	_, err := s.Return(s.ID + 1)
+	return err
+}}
+
+var paths = map[domain.ShipmentState][][]step{
+	domain.Created:        {{create}},
+	domain.Handled:        {{create, handle}},
+	domain.Shipped:        {{create, handle, ship}},
+	domain.Delivered:      {{create, handle, ship, deliver}},
+	domain.DeliveryFailed: {{create, handle, ship, failDelivery}},
+	domain.Cancelled: {
+		{create, cancel},
+		{create, handle, cancel},
+	},
+	domain.Returned: {
+		{create, handle, ship, deliver, giveBack},
+		{create, handle, ship, failDelivery, giveBack},
+	},
+}
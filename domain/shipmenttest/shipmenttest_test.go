@@ -0,0 +1,117 @@
+package shipmenttest_test
+
+import (
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	s := shipmenttest.AShipment().
+		WithID(7).
+		InState(domain.Shipped).
+		From("Buenos Aires").
+		To("Madrid").
+		Between("AR", "ES").
+		ShippedWith("fake", "TRK00000007").
+		Build()
+
+	assert.Equal(t, domain.Shipment{
+		ID:                 7,
+		State:              domain.Shipped,
+		Origin:             "Buenos Aires",
+		Destination:        "Madrid",
+		OriginCountry:      "AR",
+		DestinationCountry: "ES",
+		Carrier:            "fake",
+		TrackingNumber:     "TRK00000007",
+	}, s)
+}
+
+func TestBuilder_Build_DoesNotShareParcels(t *testing.T) {
+	b := shipmenttest.AShipment().WithParcels(domain.Parcel{Weight: 500})
+
+	first := b.Build()
+	first.Parcels[0].Weight = 1
+
+	assert.Equal(t, 500, b.Build().Parcels[0].Weight)
+}
+
+func TestRandom_Deterministic(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		s := shipmenttest.Random(seed).Build()
+
+		assert.Equal(t, s, shipmenttest.Random(seed).Build(), "seed %d", seed)
+		fresh := s
+		fresh.State = ""
+		assert.Nilf(t, fresh.Create(), "expected seed %d to build a valid shipment", seed)
+	}
+}
+
+func TestBringTo(t *testing.T) {
+	states := []domain.ShipmentState{
+		domain.Created,
+		domain.Handled,
+		domain.Shipped,
+		domain.Delivered,
+		domain.DeliveryFailed,
+		domain.Cancelled,
+		domain.Returned,
+	}
+
+	for _, state := range states {
+		t.Run(string(state), func(t *testing.T) {
+			s := shipmenttest.BringTo(t, shipmenttest.AShipment().Build(), state)
+			assert.Equal(t, state, s.State)
+		})
+	}
+}
+
+func TestBringTo_FillsTransitionFields(t *testing.T) {
+	s := shipmenttest.BringTo(t, shipmenttest.AShipment().InState(domain.Handled).Build(), domain.Delivered)
+
+	assert.Equal(t, "TRK00000001", s.TrackingNumber)
+	if assert.NotNil(t, s.Proof) {
+		assert.NotEmpty(t, s.Proof.SignatureHash)
+	}
+}
+
+func TestBringTo_FromAnyRoute(t *testing.T) {
+	cases := []struct {
+		name  string
+		from  domain.ShipmentState
+		to    domain.ShipmentState
+		check func(t *testing.T, s domain.Shipment)
+	}{
+		{
+			name: "Handled To Cancelled",
+			from: domain.Handled,
+			to:   domain.Cancelled,
+			check: func(t *testing.T, s domain.Shipment) {
+				if assert.NotNil(t, s.Cancellation) {
+					assert.Equal(t, domain.Handled, s.Cancellation.FromState)
+				}
+			},
+		},
+		{
+			name: "Delivery Failed To Returned",
+			from: domain.DeliveryFailed,
+			to:   domain.Returned,
+			check: func(t *testing.T, s domain.Shipment) {
+				assert.Len(t, s.Attempts, 1)
+				assert.Nil(t, s.Proof)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := shipmenttest.BringTo(t, shipmenttest.AShipment().Build(), c.from)
+			s = shipmenttest.BringTo(t, s, c.to)
+			assert.Equal(t, c.to, s.State)
+			c.check(t, s)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type ServiceLevel string
+
+var Express = ServiceLevel("Express")
+var Standard = ServiceLevel("Standard")
+var Economy = ServiceLevel("Economy")
+
+type SLAStatus string
+
+var OnTime = SLAStatus("OnTime")
+var AtRisk = SLAStatus("AtRisk")
+var Breached = SLAStatus("Breached")
+
+var InvalidServiceLevel = errors.New("Invalid service level")
+
+type Route struct {
+	Origin      string
+	Destination string
+}
+
+type SLAPolicy struct {
+	Transit      map[ServiceLevel]time.Duration
+	Routes       map[Route]time.Duration
+	AtRiskWindow time.Duration
+}
+
+func DefaultSLAPolicy() SLAPolicy {
+	return SLAPolicy{
+		Transit: map[ServiceLevel]time.Duration{
+			Express:  24 * time.Hour,
+			Standard: 72 * time.Hour,
+			Economy:  7 * 24 * time.Hour,
+		},
+		AtRiskWindow: 12 * time.Hour,
+	}
+}
+
+// PromisedBy adds the transit time of the service level, plus any extra
+// time configured for the route, to the given creation time.
+func (p SLAPolicy) PromisedBy(level ServiceLevel, r Route, from time.Time) (time.Time, error) {
+	transit, ok := p.Transit[level]
+	if !ok {
+		return time.Time{}, InvalidServiceLevel
+	}
+
+	return from.Add(transit + p.Routes[r]), nil
+}
+
+func (p SLAPolicy) Status(s Shipment, now time.Time) SLAStatus {
+	if s.PromisedBy.IsZero() {
+		return OnTime
+	}
+
+	switch s.State {
+	case Delivered:
+		if s.Proof != nil && s.Proof.DeliveredAt.After(s.PromisedBy) {
+			return Breached
+		}
+		return OnTime
+	case Cancelled, Returned:
+		return OnTime
+	}
+
+	if now.After(s.PromisedBy) {
+		return Breached
+	}
+	if s.PromisedBy.Sub(now) <= p.AtRiskWindow {
+		return AtRisk
+	}
+
+	return OnTime
+}
+
+func (s *Shipment) PromiseDelivery(p SLAPolicy, at time.Time) error {
+	promisedBy, err := p.PromisedBy(s.ServiceLevel, Route{s.Origin, s.Destination}, at)
+	if err != nil {
+		return err
+	}
+
+	s.CreatedAt = at
+	s.PromisedBy = promisedBy
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLAPolicy_PromisedBy_InvalidServiceLevel(t *testing.T) {
+	p := domain.DefaultSLAPolicy()
+
+	promisedBy, err := p.PromisedBy(domain.ServiceLevel("Teleport"), domain.Route{}, time.Now())
+
+	assert.Equal(t, domain.InvalidServiceLevel, err)
+	assert.Zero(t, promisedBy)
+}
+
+func TestSLAPolicy_PromisedBy_OK(t *testing.T) {
+	from := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	route := domain.Route{Origin: "valid origin", Destination: "remote destination"}
+	p := domain.DefaultSLAPolicy()
+	p.Routes = map[domain.Route]time.Duration{
+		route: 48 * time.Hour,
+	}
+
+	cases := []struct {
+		name     string
+		level    domain.ServiceLevel
+		route    domain.Route
+		expected time.Time
+	}{
+		{
+			name:     "Express",
+			level:    domain.Express,
+			expected: from.Add(24 * time.Hour),
+		},
+		{
+			name:     "Standard",
+			level:    domain.Standard,
+			expected: from.Add(72 * time.Hour),
+		},
+		{
+			name:     "Standard Remote Route",
+			level:    domain.Standard,
+			route:    route,
+			expected: from.Add(120 * time.Hour),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			promisedBy, err := p.PromisedBy(c.level, c.route, from)
+			assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+			assert.Equal(t, c.expected, promisedBy)
+		})
+	}
+}
+
+func TestSLAPolicy_Status(t *testing.T) {
+	promisedBy := time.Date(2019, 10, 4, 10, 0, 0, 0, time.UTC)
+	p := domain.DefaultSLAPolicy()
+
+	cases := []struct {
+		name     string
+		shipment domain.Shipment
+		now      time.Time
+		expected domain.SLAStatus
+	}{
+		{
+			name:     "Without Promise",
+			shipment: domain.Shipment{State: domain.Created},
+			now:      promisedBy.Add(time.Hour),
+			expected: domain.OnTime,
+		},
+		{
+			name:     "On Time",
+			shipment: domain.Shipment{State: domain.Shipped, PromisedBy: promisedBy},
+			now:      promisedBy.Add(-24 * time.Hour),
+			expected: domain.OnTime,
+		},
+		{
+			name:     "At Risk",
+			shipment: domain.Shipment{State: domain.Shipped, PromisedBy: promisedBy},
+			now:      promisedBy.Add(-6 * time.Hour),
+			expected: domain.AtRisk,
+		},
+		{
+			name:     "Breached",
+			shipment: domain.Shipment{State: domain.Shipped, PromisedBy: promisedBy},
+			now:      promisedBy.Add(time.Minute),
+			expected: domain.Breached,
+		},
+		{
+			name: "Delivered On Time",
+			shipment: domain.Shipment{
+				State:      domain.Delivered,
+				PromisedBy: promisedBy,
+				Proof:      &domain.ProofOfDelivery{DeliveredAt: promisedBy.Add(-time.Hour)},
+			},
+			now:      promisedBy.Add(48 * time.Hour),
+			expected: domain.OnTime,
+		},
+		{
+			name: "Delivered Late",
+			shipment: domain.Shipment{
+				State:      domain.Delivered,
+				PromisedBy: promisedBy,
+				Proof:      &domain.ProofOfDelivery{DeliveredAt: promisedBy.Add(time.Hour)},
+			},
+			now:      promisedBy.Add(48 * time.Hour),
+			expected: domain.Breached,
+		},
+		{
+			name:     "Cancelled",
+			shipment: domain.Shipment{State: domain.Cancelled, PromisedBy: promisedBy},
+			now:      promisedBy.Add(time.Hour),
+			expected: domain.OnTime,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, p.Status(c.shipment, c.now))
+		})
+	}
+}
+
+func TestShipment_PromiseDelivery_Error(t *testing.T) {
+	s := domain.Shipment{
+		State: domain.Created,
+	}
+
+	err := s.PromiseDelivery(domain.DefaultSLAPolicy(), time.Now())
+
+	assert.Equal(t, domain.InvalidServiceLevel, err)
+	assert.Zero(t, s.CreatedAt)
+	assert.Zero(t, s.PromisedBy)
+}
+
+func TestShipment_PromiseDelivery_OK(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	s := domain.Shipment{
+		State:        domain.Created,
+		ServiceLevel: domain.Express,
+	}
+
+	err := s.PromiseDelivery(domain.DefaultSLAPolicy(), at)
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, at, s.CreatedAt)
+	assert.Equal(t, at.Add(24*time.Hour), s.PromisedBy)
+}
@@ -0,0 +1,250 @@
+package domain_test
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/stretchr/testify/assert"
+)
+
+var seed = flag.Int64("seed", 0, "seed for the state machine properties, random when 0")
+var runs = flag.Int("runs", 500, "number of operation sequences checked per property")
+
+// op is one call against the shipment. Its name is what gets reported when a
+// sequence breaks a property, so it carries the arguments too.
+type op struct {
+	name  string
+	apply func(*domain.Shipment) error
+}
+
+func (o op) String() string {
+	return o.name
+}
+
+var at = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+
+var ops = []op{
+	{"Create", func(s *domain.Shipment) error { return s.Create() }},
+	{"Handle", func(s *domain.Shipment) error { return s.Handle() }},
+	{"Ship(fake, TRK1)", func(s *domain.Shipment) error { return s.Ship("fake", "TRK1") }},
+	{"Ship(fake, )", func(s *domain.Shipment) error { return s.Ship("fake", "") }},
+	{"Deliver(valid)", func(s *domain.Shipment) error { return s.Deliver(shipmenttest.ValidProof()) }},
+	{"Deliver(empty)", func(s *domain.Shipment) error { return s.Deliver(domain.ProofOfDelivery{}) }},
+	{"Cancel(CustomerRequest)", func(s *domain.Shipment) error {
+		return s.Cancel(domain.CustomerRequest, domain.DefaultFeeSchedule(), at)
+	}},
+	{"Cancel(Teleported)", func(s *domain.Shipment) error {
+		return s.Cancel(domain.CancellationReason("Teleported"), domain.DefaultFeeSchedule(), at)
+	}},
+	{"PlaceHold", func(s *domain.Shipment) error { return s.PlaceHold("address check", "support", at) }},
+	{"Release", func(s *domain.Shipment) error { return s.Release() }},
+	{"FailDelivery(RecipientAbsent, 2)", func(s *domain.Shipment) error { return s.FailDelivery(domain.RecipientAbsent, at, 2) }},
+	{"FailDelivery(Refused, 2)", func(s *domain.Shipment) error { return s.FailDelivery(domain.Refused, at, 2) }},
+	{"Return(2)", func(s *domain.Shipment) error {
+		_, err := s.Return(2)
+		return err
+	}},
+	{"AddLeg(valid origin, valid destination)", func(s *domain.Shipment) error {
+		return s.AddLeg(domain.Leg{From: "valid origin", To: "valid destination", Carrier: "fake", Mode: domain.Ground})
+	}},
+	{"DepartLeg(0)", func(s *domain.Shipment) error { return s.DepartLeg(0, at) }},
+	{"ArriveLeg(0)", func(s *domain.Shipment) error { return s.ArriveLeg(0, at) }},
+}
+
+// transitions lists every state change a single successful call may cause.
+// States without an entry, other than the empty one, are final. It is kept
+// here, apart from the code it checks, so the two can not drift together.
+var transitions = map[domain.ShipmentState][]domain.ShipmentState{
+	"":             {domain.Created},
+	domain.Created: {domain.Handled, domain.Cancelled},
+	domain.Handled: {domain.Shipped, domain.Cancelled},
+	domain.Shipped: {domain.Delivered, domain.DeliveryFailed},
+	// Delivered used to be final. Returns made Delivered -> Returned legal,
+	// see TestShipment_StateMachine_DeliveredIsNotFinal.
+	domain.Delivered:      {domain.Returned},
+	domain.DeliveryFailed: {domain.Returned},
+}
+
+// property inspects one step: the shipment before and after applying o and
+// the error it returned. A non-empty result describes the violation.
+type property func(before domain.Shipment, o op, after domain.Shipment, err error) string
+
+func neverLeavesFinalStates(before domain.Shipment, o op, after domain.Shipment, err error) string {
+	if _, ok := transitions[before.State]; ok {
+		return ""
+	}
+	if after.State != before.State {
+		return fmt.Sprintf("left final state '%s' for '%s'", before.State, after.State)
+	}
+	return ""
+}
+
+func onlyLegalTransitions(before domain.Shipment, o op, after domain.Shipment, err error) string {
+	if before.State == after.State {
+		return ""
+	}
+	for _, to := range transitions[before.State] {
+		if to == after.State {
+			return ""
+		}
+	}
+	return fmt.Sprintf("moved from '%s' to '%s'", before.State, after.State)
+}
+
+func errorsLeaveShipmentUnchanged(before domain.Shipment, o op, after domain.Shipment, err error) string {
+	if err != nil && !reflect.DeepEqual(before, after) {
+		return fmt.Sprintf("failed with '%s' but changed the shipment to %#v", err, after)
+	}
+	return ""
+}
+
+// run applies the sequence to a fresh shipment and returns the index of the
+// step that broke the property, or -1.
+func run(p property, sequence []op) (int, string) {
+	s, _ := domain.NewShipment(1, "valid origin", "valid destination")
+	for i, o := range sequence {
+		before := s.Clone()
+		err := o.apply(&s)
+		if violation := p(before, o, s, err); violation != "" {
+			return i, violation
+		}
+	}
+	return -1, ""
+}
+
+func generate(r *rand.Rand, length int) []op {
+	sequence := make([]op, length)
+	for i := range sequence {
+		sequence[i] = ops[r.Intn(len(ops))]
+	}
+	return sequence
+}
+
+// shrink removes ever smaller chunks of a failing sequence for as long as
+// the property keeps failing, ending with a sequence where dropping any
+// single call makes it pass.
+func shrink(p property, sequence []op) []op {
+	if i, _ := run(p, sequence); i >= 0 {
+		sequence = sequence[:i+1]
+	}
+
+	for chunk := len(sequence) / 2; chunk > 0; chunk /= 2 {
+		for start := 0; start+chunk <= len(sequence); {
+			candidate := append(append([]op(nil), sequence[:start]...), sequence[start+chunk:]...)
+			if i, _ := run(p, candidate); i >= 0 {
+				sequence = candidate[:i+1]
+				continue
+			}
+			start++
+		}
+	}
+
+	return sequence
+}
+
+func check(t *testing.T, p property) {
+	s := *seed
+	if s == 0 {
+		s = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(s))
+
+	for n := 0; n < *runs; n++ {
+		sequence := generate(r, 1+r.Intn(20))
+		if i, _ := run(p, sequence); i < 0 {
+			continue
+		}
+
+		minimal := shrink(p, sequence)
+		_, violation := run(p, minimal)
+		t.Fatalf("property failed with -seed=%d after %s: %s", s, describe(minimal), violation)
+	}
+}
+
+func describe(sequence []op) string {
+	names := make([]string, len(sequence))
+	for i, o := range sequence {
+		names[i] = o.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+func TestShipment_StateMachine_NeverLeavesFinalStates(t *testing.T) {
+	check(t, neverLeavesFinalStates)
+}
+
+func TestShipment_StateMachine_OnlyLegalTransitions(t *testing.T) {
+	check(t, onlyLegalTransitions)
+}
+
+func TestShipment_StateMachine_ErrorsLeaveShipmentUnchanged(t *testing.T) {
+	check(t, errorsLeaveShipmentUnchanged)
+}
+
+func TestShipment_StateMachine_Shrink(t *testing.T) {
+	neverShips := func(before domain.Shipment, o op, after domain.Shipment, err error) string {
+		if after.State == domain.Shipped {
+			return "shipped"
+		}
+		return ""
+	}
+	r := rand.New(rand.NewSource(1))
+
+	var failing []op
+	for failing == nil {
+		sequence := generate(r, 40)
+		if i, _ := run(neverShips, sequence); i >= 0 {
+			failing = sequence
+		}
+	}
+
+	assert.Equal(t, "Create, Handle, Ship(fake, TRK1)", describe(shrink(neverShips, failing)))
+}
+
+// Every transition in the table has to be reachable through ops, otherwise
+// the properties above never exercise it.
+func TestShipment_StateMachine_CoversEveryTransition(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	seen := map[[2]domain.ShipmentState]bool{}
+	for n := 0; n < 2000; n++ {
+		s, _ := domain.NewShipment(1, "valid origin", "valid destination")
+		for _, o := range generate(r, 20) {
+			before := s.State
+			if o.apply(&s) == nil {
+				seen[[2]domain.ShipmentState{before, s.State}] = true
+			}
+		}
+	}
+
+	for from, targets := range transitions {
+		for _, to := range targets {
+			assert.True(t, seen[[2]domain.ShipmentState{from, to}], "expected ops to move a shipment from '%s' to '%s'", from, to)
+		}
+	}
+}
+
+// The first version of these properties held that a delivered shipment never
+// changes state again. Returns redefined that: Delivered -> Returned is legal
+// and only Cancelled and Returned are final.
+func TestShipment_StateMachine_DeliveredIsNotFinal(t *testing.T) {
+	s := shipmenttest.AShipment().InState(domain.Delivered).Build()
+
+	_, err := s.Return(2)
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.Returned, s.State)
+	var final []domain.ShipmentState
+	for _, state := range []domain.ShipmentState{domain.Created, domain.Handled, domain.Shipped, domain.Cancelled, domain.Delivered, domain.DeliveryFailed, domain.Returned} {
+		if _, ok := transitions[state]; !ok {
+			final = append(final, state)
+		}
+	}
+	assert.Equal(t, []domain.ShipmentState{domain.Cancelled, domain.Returned}, final)
+}
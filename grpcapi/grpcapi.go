@@ -0,0 +1,257 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/facucachomeli/workshop-go-testing/config"
+	"github.com/facucachomeli/workshop-go-testing/dangerousgoods"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/proto/shipmentpb"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const AuthorizationMetadata = "authorization"
+
+type server struct {
+	shipmentpb.UnimplementedShipmentServiceServer
+	app *config.App
+}
+
+// New exposes the shipment use cases of app over gRPC. Every call
+// authenticates with "authorization: Bearer <key>" metadata, where the key is
+// one of the configured clients, and acts as that client's actor on the
+// shipments of its tenant.
+func New(app *config.App, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	shipmentpb.RegisterShipmentServiceServer(s, server{app: app})
+
+	return s
+}
+
+func (s server) GetShipment(ctx context.Context, req *shipmentpb.ShipmentRequest) (*shipmentpb.Shipment, error) {
+	return s.transition(ctx, req, config.Shipments.Get)
+}
+
+func (s server) CreateShipment(ctx context.Context, req *shipmentpb.CreateShipmentRequest) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []usecase.CreateOption
+	if req.ServiceLevel != "" {
+		opts = append(opts, usecase.WithServiceLevel(domain.ServiceLevel(req.ServiceLevel)))
+	}
+	if req.OriginCountry != "" || req.DestinationCountry != "" {
+		opts = append(opts, usecase.WithCountries(req.OriginCountry, req.DestinationCountry))
+	}
+	if len(req.Parcels) > 0 {
+		opts = append(opts, usecase.WithParcels(parcelsFromProto(req.Parcels)...))
+	}
+
+	return respond(s.app.Shipments(client.Tenant).Create(actorOf(client), req.Origin, req.Destination, opts...))
+}
+
+func (s server) HandleShipment(ctx context.Context, req *shipmentpb.ShipmentRequest) (*shipmentpb.Shipment, error) {
+	return s.transition(ctx, req, config.Shipments.Handle)
+}
+
+func (s server) ShipShipment(ctx context.Context, req *shipmentpb.ShipmentRequest) (*shipmentpb.Shipment, error) {
+	return s.transition(ctx, req, config.Shipments.Ship)
+}
+
+func (s server) DeliverShipment(ctx context.Context, req *shipmentpb.DeliverShipmentRequest) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return respond(s.app.Shipments(client.Tenant).Deliver(actorOf(client), domain.ShipmentID(req.Id), proofFromProto(req.Proof)))
+}
+
+func (s server) CancelShipment(ctx context.Context, req *shipmentpb.CancelShipmentRequest) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return respond(s.app.Shipments(client.Tenant).Cancel(actorOf(client), domain.ShipmentID(req.Id), domain.CancellationReason(req.Reason)))
+}
+
+func (s server) InitiateReturn(ctx context.Context, req *shipmentpb.ShipmentRequest) (*shipmentpb.Shipment, error) {
+	return s.transition(ctx, req, config.Shipments.InitiateReturn)
+}
+
+func (s server) GetProofOfDelivery(ctx context.Context, req *shipmentpb.ShipmentRequest) (*shipmentpb.ProofOfDelivery, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := s.app.Shipments(client.Tenant).ProofOfDelivery(actorOf(client), domain.ShipmentID(req.Id))
+	if err != nil {
+		return nil, status.Error(codeOf(err), err.Error())
+	}
+
+	return proofToProto(proof), nil
+}
+
+func (s server) ReportDeliveryAttempt(ctx context.Context, req *shipmentpb.ReportDeliveryAttemptRequest) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return respond(s.app.Shipments(client.Tenant).ReportDeliveryAttempt(actorOf(client), domain.ShipmentID(req.Id), domain.FailureReason(req.Reason)))
+}
+
+func (s server) DeclareCustoms(ctx context.Context, req *shipmentpb.DeclareCustomsRequest) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return respond(s.app.Shipments(client.Tenant).DeclareCustoms(actorOf(client), domain.ShipmentID(req.Id), customsFromProto(req.Customs)))
+}
+
+func (s server) PlanLeg(ctx context.Context, req *shipmentpb.PlanLegRequest) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return respond(s.app.Shipments(client.Tenant).PlanLeg(actorOf(client), domain.ShipmentID(req.Id), legFromProto(req.Leg)))
+}
+
+func (s server) DepartLeg(ctx context.Context, req *shipmentpb.LegRequest) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return respond(s.app.Shipments(client.Tenant).DepartLeg(actorOf(client), domain.ShipmentID(req.Id), int(req.Leg)))
+}
+
+func (s server) ArriveLeg(ctx context.Context, req *shipmentpb.LegRequest) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return respond(s.app.Shipments(client.Tenant).ArriveLeg(actorOf(client), domain.ShipmentID(req.Id), int(req.Leg)))
+}
+
+func (s server) PlaceHold(ctx context.Context, req *shipmentpb.PlaceHoldRequest) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return respond(s.app.Holds(client.Tenant).PlaceHold(actorOf(client), domain.ShipmentID(req.Id), req.Reason))
+}
+
+func (s server) ReleaseHold(ctx context.Context, req *shipmentpb.ShipmentRequest) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return respond(s.app.Holds(client.Tenant).Release(actorOf(client), domain.ShipmentID(req.Id)))
+}
+
+func (s server) ListOnHold(ctx context.Context, req *shipmentpb.ListOnHoldRequest) (*shipmentpb.ListOnHoldResponse, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	held, err := s.app.Holds(client.Tenant).OnHold(actorOf(client))
+	if err != nil {
+		return nil, status.Error(codeOf(err), err.Error())
+	}
+
+	res := &shipmentpb.ListOnHoldResponse{}
+	for _, h := range held {
+		res.Shipments = append(res.Shipments, shipmentToProto(h))
+	}
+
+	return res, nil
+}
+
+func (s server) transition(ctx context.Context, req *shipmentpb.ShipmentRequest, call func(config.Shipments, usecase.Actor, domain.ShipmentID) (domain.Shipment, error)) (*shipmentpb.Shipment, error) {
+	client, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return respond(call(s.app.Shipments(client.Tenant), actorOf(client), domain.ShipmentID(req.Id)))
+}
+
+// authenticate finds the configured client whose key the call carries.
+func (s server) authenticate(ctx context.Context) (config.Client, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get(AuthorizationMetadata) {
+		if key, ok := strings.CutPrefix(v, "Bearer "); ok {
+			if client, ok := s.app.Authenticate(key); ok {
+				return client, nil
+			}
+		}
+	}
+
+	return config.Client{}, status.Error(codes.Unauthenticated, "Unauthorized")
+}
+
+func actorOf(c config.Client) usecase.Actor {
+	return usecase.Actor{ID: c.Actor, Role: c.Role}
+}
+
+func respond(s domain.Shipment, err error) (*shipmentpb.Shipment, error) {
+	if err != nil {
+		return nil, status.Error(codeOf(err), err.Error())
+	}
+	return shipmentToProto(s), nil
+}
+
+func codeOf(err error) codes.Code {
+	if _, ok := err.(dangerousgoods.Violations); ok {
+		return codes.FailedPrecondition
+	}
+
+	switch err {
+	case usecase.Forbidden:
+		return codes.PermissionDenied
+	case usecase.ShipmentDoesNotExist, usecase.ProofOfDeliveryNotFound:
+		return codes.NotFound
+	case usecase.ShipmentAlreadyExists:
+		return codes.AlreadyExists
+	case usecase.CouldNotCreateShipment, usecase.InvalidProofOfDelivery:
+		return codes.InvalidArgument
+	case usecase.ShipmentOnHold,
+		usecase.ShipmentCanNotBeHandled,
+		usecase.ShipmentCanNotBeShipped,
+		usecase.ShipmentCanNotBeDelivered,
+		usecase.ShipmentCanNotBeCancelled,
+		usecase.ShipmentCanNotBeReturned,
+		usecase.IncompleteCustomsDeclaration,
+		usecase.DeliveryAttemptCanNotBeReported,
+		usecase.CustomsCanNotBeDeclared,
+		usecase.LegCanNotBePlanned,
+		usecase.LegCanNotDepart,
+		usecase.LegCanNotArrive,
+		usecase.ShipmentCanNotBeHeld,
+		usecase.ShipmentCanNotBeReleased:
+		return codes.FailedPrecondition
+	case usecase.NoCarrierAvailable, usecase.CouldNotBookPickup, usecase.CouldNotRequestRefund:
+		return codes.Unavailable
+	case usecase.CouldNotSaveShipment:
+		// Saves fail mostly on version conflicts, which the caller can retry.
+		return codes.Aborted
+	}
+
+	return codes.Internal
+}
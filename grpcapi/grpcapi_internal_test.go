@@ -0,0 +1,157 @@
+package grpcapi
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/dangerousgoods"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/proto/shipmentpb"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCodeOf(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected codes.Code
+	}{
+		{usecase.Forbidden, codes.PermissionDenied},
+		{usecase.ShipmentDoesNotExist, codes.NotFound},
+		{usecase.ProofOfDeliveryNotFound, codes.NotFound},
+		{usecase.ShipmentAlreadyExists, codes.AlreadyExists},
+		{usecase.CouldNotCreateShipment, codes.InvalidArgument},
+		{usecase.InvalidProofOfDelivery, codes.InvalidArgument},
+		{usecase.ShipmentOnHold, codes.FailedPrecondition},
+		{usecase.ShipmentCanNotBeHandled, codes.FailedPrecondition},
+		{usecase.ShipmentCanNotBeShipped, codes.FailedPrecondition},
+		{usecase.ShipmentCanNotBeDelivered, codes.FailedPrecondition},
+		{usecase.ShipmentCanNotBeCancelled, codes.FailedPrecondition},
+		{usecase.ShipmentCanNotBeReturned, codes.FailedPrecondition},
+		{usecase.IncompleteCustomsDeclaration, codes.FailedPrecondition},
+		{usecase.DeliveryAttemptCanNotBeReported, codes.FailedPrecondition},
+		{usecase.CustomsCanNotBeDeclared, codes.FailedPrecondition},
+		{usecase.LegCanNotBePlanned, codes.FailedPrecondition},
+		{usecase.LegCanNotDepart, codes.FailedPrecondition},
+		{usecase.LegCanNotArrive, codes.FailedPrecondition},
+		{usecase.ShipmentCanNotBeHeld, codes.FailedPrecondition},
+		{usecase.ShipmentCanNotBeReleased, codes.FailedPrecondition},
+		{dangerousgoods.Violations{{UNNumber: "UN3481", Rule: "Forbidden by air"}}, codes.FailedPrecondition},
+		{usecase.NoCarrierAvailable, codes.Unavailable},
+		{usecase.CouldNotBookPickup, codes.Unavailable},
+		{usecase.CouldNotRequestRefund, codes.Unavailable},
+		{usecase.CouldNotCheckExistingShipment, codes.Internal},
+		{usecase.CouldNotSaveShipment, codes.Aborted},
+		{errors.New("Disk full"), codes.Internal},
+	}
+
+	for _, c := range cases {
+		t.Run(c.err.Error(), func(t *testing.T) {
+			assert.Equal(t, c.expected, codeOf(c.err))
+		})
+	}
+}
+
+func TestShipmentToProto(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	s := domain.Shipment{
+		ID:             2,
+		Tenant:         "acme",
+		Version:        4,
+		State:          domain.Cancelled,
+		Origin:         "Buenos Aires",
+		Destination:    "Madrid",
+		ReturnOf:       1,
+		Carrier:        "fake",
+		TrackingNumber: "TRK00000002",
+		Attempts:       []domain.DeliveryAttempt{{Reason: domain.RecipientAbsent, At: at}},
+		Proof:          &domain.ProofOfDelivery{RecipientName: "Jane Doe", SignatureHash: "d2f1e4b3", Latitude: -34.6, Longitude: -58.4, DeliveredAt: at},
+		Legs:           []domain.Leg{{From: "Buenos Aires", To: "Madrid", Carrier: "fake", Mode: domain.Air, PlannedDeparture: at}},
+		Parcels: []domain.Parcel{{
+			Weight:         1500,
+			DangerousGoods: []domain.DangerousGood{{UNNumber: "UN3481", Class: 9, Description: "Lithium ion batteries packed with equipment"}},
+		}},
+		OriginCountry:      "AR",
+		DestinationCountry: "ES",
+		Customs: &domain.CustomsDeclaration{
+			Items:    []domain.CustomsItem{{HSCode: "6109.10", Description: "Cotton t-shirt", Quantity: 3, UnitValue: 1250}},
+			Currency: "USD",
+			Incoterm: domain.DAP,
+			Exporter: domain.Party{Name: "Exporter SA", Address: "Av. Corrientes 1234, Buenos Aires", Country: "AR", TaxID: "30-12345678-9"},
+			Importer: domain.Party{Name: "Importer SL", Address: "Gran Via 1, Madrid", Country: "ES"},
+		},
+		Hold:         &domain.Hold{Reason: "address check", By: "support", At: at},
+		Cancellation: &domain.Cancellation{Reason: domain.CustomerRequest, FromState: domain.Handled, Fee: 500, Currency: "USD", At: at, RefundRequested: true},
+		ServiceLevel: domain.Express,
+		CreatedAt:    at,
+	}
+	ts := timestamppb.New(at)
+
+	expected := &shipmentpb.Shipment{
+		Id:             2,
+		Tenant:         "acme",
+		Version:        4,
+		State:          shipmentpb.ShipmentState_SHIPMENT_STATE_CANCELLED,
+		Origin:         "Buenos Aires",
+		Destination:    "Madrid",
+		ReturnOf:       1,
+		Carrier:        "fake",
+		TrackingNumber: "TRK00000002",
+		Attempts:       []*shipmentpb.DeliveryAttempt{{Reason: "RecipientAbsent", At: ts}},
+		Proof:          &shipmentpb.ProofOfDelivery{RecipientName: "Jane Doe", SignatureHash: "d2f1e4b3", Latitude: -34.6, Longitude: -58.4, DeliveredAt: ts},
+		Legs:           []*shipmentpb.Leg{{From: "Buenos Aires", To: "Madrid", Carrier: "fake", Mode: "Air", PlannedDeparture: ts}},
+		Parcels: []*shipmentpb.Parcel{{
+			Weight:         1500,
+			DangerousGoods: []*shipmentpb.DangerousGood{{UnNumber: "UN3481", Class: 9, Description: "Lithium ion batteries packed with equipment"}},
+		}},
+		OriginCountry:      "AR",
+		DestinationCountry: "ES",
+		Customs: &shipmentpb.CustomsDeclaration{
+			Items:    []*shipmentpb.CustomsItem{{HsCode: "6109.10", Description: "Cotton t-shirt", Quantity: 3, UnitValue: 1250}},
+			Currency: "USD",
+			Incoterm: "DAP",
+			Exporter: &shipmentpb.Party{Name: "Exporter SA", Address: "Av. Corrientes 1234, Buenos Aires", Country: "AR", TaxId: "30-12345678-9"},
+			Importer: &shipmentpb.Party{Name: "Importer SL", Address: "Gran Via 1, Madrid", Country: "ES"},
+		},
+		Hold:         &shipmentpb.Hold{Reason: "address check", By: "support", At: ts},
+		Cancellation: &shipmentpb.Cancellation{Reason: "CustomerRequest", FromState: shipmentpb.ShipmentState_SHIPMENT_STATE_HANDLED, Fee: 500, Currency: "USD", At: ts, RefundRequested: true},
+		ServiceLevel: "Express",
+		CreatedAt:    ts,
+	}
+
+	actual := shipmentToProto(s)
+
+	assert.True(t, proto.Equal(expected, actual), "expected %v but got %v", expected, actual)
+	assert.Nil(t, actual.PromisedBy, "expected zero times to be left unset")
+}
+
+func TestFromProto(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	proof := proofFromProto(&shipmentpb.ProofOfDelivery{RecipientName: "Jane Doe", Signature: []byte{1, 2}, DeliveredAt: timestamppb.New(at)})
+	parcels := parcelsFromProto([]*shipmentpb.Parcel{{Weight: 1500, DangerousGoods: []*shipmentpb.DangerousGood{{UnNumber: "UN3481", Class: 9}}}})
+	leg := legFromProto(&shipmentpb.Leg{From: "Buenos Aires", To: "Madrid", Carrier: "fake", Mode: "Air", PlannedDeparture: timestamppb.New(at), ActualDeparture: timestamppb.New(at)})
+	customs := customsFromProto(&shipmentpb.CustomsDeclaration{
+		Items:    []*shipmentpb.CustomsItem{{HsCode: "610910", Description: "Cotton t-shirt", Quantity: 3, UnitValue: 1250}},
+		Currency: "USD",
+		Incoterm: "DAP",
+		Exporter: &shipmentpb.Party{Name: "Exporter SA", Country: "AR", TaxId: "30-12345678-9"},
+	})
+
+	assert.Equal(t, domain.ProofOfDelivery{RecipientName: "Jane Doe", Signature: []byte{1, 2}, DeliveredAt: at}, proof)
+	assert.Equal(t, domain.ProofOfDelivery{}, proofFromProto(nil))
+	assert.Equal(t, []domain.Parcel{{Weight: 1500, DangerousGoods: []domain.DangerousGood{{UNNumber: "UN3481", Class: 9}}}}, parcels)
+	assert.Equal(t, domain.Leg{From: "Buenos Aires", To: "Madrid", Carrier: "fake", Mode: domain.Air, PlannedDeparture: at}, leg, "expected actual times to be left to the use cases")
+	assert.Equal(t, domain.CustomsDeclaration{
+		Items:    []domain.CustomsItem{{HSCode: "610910", Description: "Cotton t-shirt", Quantity: 3, UnitValue: 1250}},
+		Currency: "USD",
+		Incoterm: domain.DAP,
+		Exporter: domain.Party{Name: "Exporter SA", Country: "AR", TaxID: "30-12345678-9"},
+	}, customs)
+	assert.Equal(t, domain.Leg{}, legFromProto(nil))
+	assert.Equal(t, domain.CustomsDeclaration{}, customsFromProto(nil))
+}
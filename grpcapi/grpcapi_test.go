@@ -0,0 +1,328 @@
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/config"
+	"github.com/facucachomeli/workshop-go-testing/grpcapi"
+	"github.com/facucachomeli/workshop-go-testing/proto/shipmentpb"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newClient serves an app with one acme client per role, keyed
+// "acme-<role>", and a globex admin keyed "globex-admin", over an
+// in-process listener.
+func newClient(t *testing.T) shipmentpb.ShipmentServiceClient {
+	c := config.Default()
+	c.Carriers = []config.Carrier{{Name: "fast", Type: config.FakeCarrier, Prefix: "FST"}}
+	c.DefaultCarrier = "fast"
+	for _, role := range []string{"merchant", "warehouse", "driver", "admin"} {
+		c.Clients = append(c.Clients, config.Client{Key: "acme-" + role, Tenant: "acme", Actor: role + "-1", Role: usecase.Role(role)})
+	}
+	c.Clients = append(c.Clients, config.Client{Key: "globex-admin", Tenant: "globex", Actor: "admin-1", Role: usecase.Admin})
+	app, err := config.Build(c)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpcapi.New(app)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return shipmentpb.NewShipmentServiceClient(conn)
+}
+
+func as(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), grpcapi.AuthorizationMetadata, "Bearer "+key)
+}
+
+func create(t *testing.T, client shipmentpb.ShipmentServiceClient) *shipmentpb.Shipment {
+	s, err := client.CreateShipment(as("acme-merchant"), &shipmentpb.CreateShipmentRequest{Origin: "valid origin", Destination: "valid destination"})
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	return s
+}
+
+func TestServer_ShipmentLifecycle(t *testing.T) {
+	client := newClient(t)
+
+	s, err := client.CreateShipment(as("acme-merchant"), &shipmentpb.CreateShipmentRequest{
+		Origin:       "valid origin",
+		Destination:  "valid destination",
+		ServiceLevel: "Express",
+		Parcels:      []*shipmentpb.Parcel{{Weight: 1500}},
+	})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, shipmentpb.ShipmentState_SHIPMENT_STATE_CREATED, s.State)
+	assert.Equal(t, "acme", s.Tenant)
+	assert.Equal(t, "Express", s.ServiceLevel)
+	assert.Equal(t, int64(1500), s.Parcels[0].Weight)
+
+	s, err = client.HandleShipment(as("acme-warehouse"), &shipmentpb.ShipmentRequest{Id: s.Id})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, shipmentpb.ShipmentState_SHIPMENT_STATE_HANDLED, s.State)
+
+	s, err = client.ShipShipment(as("acme-warehouse"), &shipmentpb.ShipmentRequest{Id: s.Id})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "FST00000001", s.TrackingNumber)
+
+	s, err = client.DeliverShipment(as("acme-driver"), &shipmentpb.DeliverShipmentRequest{Id: s.Id, Proof: &shipmentpb.ProofOfDelivery{
+		RecipientName: "Jane Doe",
+		SignatureHash: "d2f1e4b3",
+		DeliveredAt:   timestamppb.Now(),
+	}})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, shipmentpb.ShipmentState_SHIPMENT_STATE_DELIVERED, s.State)
+	assert.Equal(t, "Jane Doe", s.Proof.RecipientName)
+
+	r, err := client.InitiateReturn(as("acme-merchant"), &shipmentpb.ShipmentRequest{Id: s.Id})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, s.Id, r.ReturnOf)
+
+	s, err = client.GetShipment(as("acme-driver"), &shipmentpb.ShipmentRequest{Id: s.Id})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, shipmentpb.ShipmentState_SHIPMENT_STATE_RETURNED, s.State)
+}
+
+func TestServer_CancelShipment(t *testing.T) {
+	client := newClient(t)
+	s := create(t, client)
+
+	s, err := client.CancelShipment(as("acme-merchant"), &shipmentpb.CancelShipmentRequest{Id: s.Id, Reason: "CustomerRequest"})
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, shipmentpb.ShipmentState_SHIPMENT_STATE_CANCELLED, s.State)
+	assert.Equal(t, shipmentpb.ShipmentState_SHIPMENT_STATE_CREATED, s.Cancellation.FromState)
+}
+
+func TestServer_Error(t *testing.T) {
+	client := newClient(t)
+	create(t, client)
+
+	cases := []struct {
+		name            string
+		call            func() (*shipmentpb.Shipment, error)
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{
+			name: "Unauthenticated",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.GetShipment(context.Background(), &shipmentpb.ShipmentRequest{Id: 1})
+			},
+			expectedCode:    codes.Unauthenticated,
+			expectedMessage: "Unauthorized",
+		},
+		{
+			name: "Unknown Key",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.GetShipment(as("acme-intruder"), &shipmentpb.ShipmentRequest{Id: 1})
+			},
+			expectedCode:    codes.Unauthenticated,
+			expectedMessage: "Unauthorized",
+		},
+		{
+			name: "Forbidden",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.HandleShipment(as("acme-driver"), &shipmentpb.ShipmentRequest{Id: 1})
+			},
+			expectedCode:    codes.PermissionDenied,
+			expectedMessage: "Forbidden",
+		},
+		{
+			name: "Not Found",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.GetShipment(as("acme-admin"), &shipmentpb.ShipmentRequest{Id: 9})
+			},
+			expectedCode:    codes.NotFound,
+			expectedMessage: "Shipment does not exist",
+		},
+		{
+			name: "Other Tenant",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.GetShipment(as("globex-admin"), &shipmentpb.ShipmentRequest{Id: 1})
+			},
+			expectedCode:    codes.NotFound,
+			expectedMessage: "Shipment does not exist",
+		},
+		{
+			name: "Invalid Shipment",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.CreateShipment(as("acme-merchant"), &shipmentpb.CreateShipmentRequest{Origin: "valid origin"})
+			},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "Could not create shipment",
+		},
+		{
+			name: "Invalid Proof",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.DeliverShipment(as("acme-driver"), &shipmentpb.DeliverShipmentRequest{Id: 1})
+			},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "Invalid proof of delivery",
+		},
+		{
+			name: "Forbidden Hold",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.PlaceHold(as("acme-driver"), &shipmentpb.PlaceHoldRequest{Id: 1, Reason: "address check"})
+			},
+			expectedCode:    codes.PermissionDenied,
+			expectedMessage: "Forbidden",
+		},
+		{
+			name: "Leg Not Shipped",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.DepartLeg(as("acme-warehouse"), &shipmentpb.LegRequest{Id: 1, Leg: 0})
+			},
+			expectedCode:    codes.FailedPrecondition,
+			expectedMessage: "Leg can not depart",
+		},
+		{
+			name: "Invalid Customs",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.DeclareCustoms(as("acme-merchant"), &shipmentpb.DeclareCustomsRequest{Id: 1})
+			},
+			expectedCode:    codes.FailedPrecondition,
+			expectedMessage: "Customs can not be declared",
+		},
+		{
+			name: "Invalid Transition",
+			call: func() (*shipmentpb.Shipment, error) {
+				return client.ShipShipment(as("acme-warehouse"), &shipmentpb.ShipmentRequest{Id: 1})
+			},
+			expectedCode:    codes.FailedPrecondition,
+			expectedMessage: "Shipment can not be shipped",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := c.call()
+
+			assert.Nil(t, s)
+			assert.Equal(t, c.expectedCode, status.Code(err))
+			assert.Equal(t, c.expectedMessage, status.Convert(err).Message())
+		})
+	}
+}
+
+func TestServer_Legs(t *testing.T) {
+	client := newClient(t)
+	s := create(t, client)
+	departure := timestamppb.Now()
+
+	s, err := client.PlanLeg(as("acme-warehouse"), &shipmentpb.PlanLegRequest{Id: s.Id, Leg: &shipmentpb.Leg{
+		From:             "valid origin",
+		To:               "valid destination",
+		Carrier:          "fast",
+		Mode:             "Ground",
+		PlannedDeparture: departure,
+		PlannedArrival:   timestamppb.New(departure.AsTime().Add(time.Hour)),
+	}})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Len(t, s.Legs, 1)
+
+	client.HandleShipment(as("acme-warehouse"), &shipmentpb.ShipmentRequest{Id: s.Id})
+	client.ShipShipment(as("acme-warehouse"), &shipmentpb.ShipmentRequest{Id: s.Id})
+
+	s, err = client.DepartLeg(as("acme-warehouse"), &shipmentpb.LegRequest{Id: s.Id, Leg: 0})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.NotNil(t, s.Legs[0].ActualDeparture)
+
+	s, err = client.ArriveLeg(as("acme-warehouse"), &shipmentpb.LegRequest{Id: s.Id, Leg: 0})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.NotNil(t, s.Legs[0].ActualArrival)
+}
+
+func TestServer_DeliveryAttemptAndProof(t *testing.T) {
+	client := newClient(t)
+	s := create(t, client)
+	client.HandleShipment(as("acme-warehouse"), &shipmentpb.ShipmentRequest{Id: s.Id})
+	client.ShipShipment(as("acme-warehouse"), &shipmentpb.ShipmentRequest{Id: s.Id})
+
+	s, err := client.ReportDeliveryAttempt(as("acme-driver"), &shipmentpb.ReportDeliveryAttemptRequest{Id: s.Id, Reason: "RecipientAbsent"})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Len(t, s.Attempts, 1)
+	assert.Equal(t, "RecipientAbsent", s.Attempts[0].Reason)
+
+	_, err = client.GetProofOfDelivery(as("acme-merchant"), &shipmentpb.ShipmentRequest{Id: s.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	client.DeliverShipment(as("acme-driver"), &shipmentpb.DeliverShipmentRequest{Id: s.Id, Proof: &shipmentpb.ProofOfDelivery{
+		RecipientName: "Jane Doe",
+		SignatureHash: "d2f1e4b3",
+		DeliveredAt:   timestamppb.Now(),
+	}})
+
+	proof, err := client.GetProofOfDelivery(as("acme-merchant"), &shipmentpb.ShipmentRequest{Id: s.Id})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "Jane Doe", proof.RecipientName)
+}
+
+func TestServer_DeclareCustoms(t *testing.T) {
+	client := newClient(t)
+	s, _ := client.CreateShipment(as("acme-merchant"), &shipmentpb.CreateShipmentRequest{
+		Origin:             "Buenos Aires",
+		Destination:        "Madrid",
+		OriginCountry:      "AR",
+		DestinationCountry: "ES",
+	})
+
+	s, err := client.DeclareCustoms(as("acme-merchant"), &shipmentpb.DeclareCustomsRequest{Id: s.Id, Customs: &shipmentpb.CustomsDeclaration{
+		Items:    []*shipmentpb.CustomsItem{{HsCode: "610910", Description: "Cotton t-shirt", Quantity: 3, UnitValue: 1250}},
+		Currency: "USD",
+		Incoterm: "DAP",
+		Exporter: &shipmentpb.Party{Name: "Exporter SA", Address: "Av. Corrientes 1234, Buenos Aires", Country: "AR"},
+		Importer: &shipmentpb.Party{Name: "Importer SL", Address: "Gran Via 1, Madrid", Country: "ES"},
+	}})
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "DAP", s.Customs.Incoterm)
+	assert.Equal(t, "610910", s.Customs.Items[0].HsCode)
+}
+
+func TestServer_Holds(t *testing.T) {
+	client := newClient(t)
+	s := create(t, client)
+
+	s, err := client.PlaceHold(as("acme-warehouse"), &shipmentpb.PlaceHoldRequest{Id: s.Id, Reason: "address check"})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "address check", s.Hold.Reason)
+	assert.Equal(t, "warehouse-1", s.Hold.By)
+
+	held, err := client.ListOnHold(as("acme-driver"), &shipmentpb.ListOnHoldRequest{})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	if assert.Len(t, held.Shipments, 1) {
+		assert.Equal(t, s.Id, held.Shipments[0].Id)
+	}
+	held, _ = client.ListOnHold(as("globex-admin"), &shipmentpb.ListOnHoldRequest{})
+	assert.Empty(t, held.Shipments, "expected other tenants' holds not to be listed")
+
+	s, err = client.ReleaseHold(as("acme-warehouse"), &shipmentpb.ShipmentRequest{Id: s.Id})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Nil(t, s.Hold)
+}
@@ -0,0 +1,225 @@
+package grpcapi
+
+import (
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/proto/shipmentpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var states = map[domain.ShipmentState]shipmentpb.ShipmentState{
+	domain.Created:        shipmentpb.ShipmentState_SHIPMENT_STATE_CREATED,
+	domain.Handled:        shipmentpb.ShipmentState_SHIPMENT_STATE_HANDLED,
+	domain.Shipped:        shipmentpb.ShipmentState_SHIPMENT_STATE_SHIPPED,
+	domain.Cancelled:      shipmentpb.ShipmentState_SHIPMENT_STATE_CANCELLED,
+	domain.Delivered:      shipmentpb.ShipmentState_SHIPMENT_STATE_DELIVERED,
+	domain.DeliveryFailed: shipmentpb.ShipmentState_SHIPMENT_STATE_DELIVERY_FAILED,
+	domain.Returned:       shipmentpb.ShipmentState_SHIPMENT_STATE_RETURNED,
+}
+
+func shipmentToProto(s domain.Shipment) *shipmentpb.Shipment {
+	p := &shipmentpb.Shipment{
+		Id:                 int64(s.ID),
+		Tenant:             string(s.Tenant),
+		Version:            int64(s.Version),
+		State:              states[s.State],
+		Origin:             s.Origin,
+		Destination:        s.Destination,
+		ReturnOf:           int64(s.ReturnOf),
+		Carrier:            s.Carrier,
+		TrackingNumber:     s.TrackingNumber,
+		OriginCountry:      s.OriginCountry,
+		DestinationCountry: s.DestinationCountry,
+		ServiceLevel:       string(s.ServiceLevel),
+		CreatedAt:          timestamp(s.CreatedAt),
+		PromisedBy:         timestamp(s.PromisedBy),
+	}
+
+	for _, a := range s.Attempts {
+		p.Attempts = append(p.Attempts, &shipmentpb.DeliveryAttempt{Reason: string(a.Reason), At: timestamp(a.At)})
+	}
+	if s.Proof != nil {
+		p.Proof = proofToProto(*s.Proof)
+	}
+	for _, l := range s.Legs {
+		p.Legs = append(p.Legs, legToProto(l))
+	}
+	for _, parcel := range s.Parcels {
+		p.Parcels = append(p.Parcels, parcelToProto(parcel))
+	}
+	if s.Customs != nil {
+		p.Customs = customsToProto(*s.Customs)
+	}
+	if s.Hold != nil {
+		p.Hold = &shipmentpb.Hold{Reason: s.Hold.Reason, By: s.Hold.By, At: timestamp(s.Hold.At)}
+	}
+	if c := s.Cancellation; c != nil {
+		p.Cancellation = &shipmentpb.Cancellation{
+			Reason:          string(c.Reason),
+			FromState:       states[c.FromState],
+			Fee:             c.Fee,
+			Currency:        c.Currency,
+			At:              timestamp(c.At),
+			RefundRequested: c.RefundRequested,
+		}
+	}
+
+	return p
+}
+
+func proofToProto(p domain.ProofOfDelivery) *shipmentpb.ProofOfDelivery {
+	return &shipmentpb.ProofOfDelivery{
+		RecipientName: p.RecipientName,
+		Signature:     p.Signature,
+		SignatureHash: p.SignatureHash,
+		PhotoRef:      p.PhotoRef,
+		Latitude:      p.Latitude,
+		Longitude:     p.Longitude,
+		DeliveredAt:   timestamp(p.DeliveredAt),
+	}
+}
+
+func proofFromProto(p *shipmentpb.ProofOfDelivery) domain.ProofOfDelivery {
+	if p == nil {
+		return domain.ProofOfDelivery{}
+	}
+
+	return domain.ProofOfDelivery{
+		RecipientName: p.RecipientName,
+		Signature:     p.Signature,
+		SignatureHash: p.SignatureHash,
+		PhotoRef:      p.PhotoRef,
+		Latitude:      p.Latitude,
+		Longitude:     p.Longitude,
+		DeliveredAt:   fromTimestamp(p.DeliveredAt),
+	}
+}
+
+func legToProto(l domain.Leg) *shipmentpb.Leg {
+	return &shipmentpb.Leg{
+		From:             l.From,
+		To:               l.To,
+		Carrier:          l.Carrier,
+		Mode:             string(l.Mode),
+		PlannedDeparture: timestamp(l.PlannedDeparture),
+		PlannedArrival:   timestamp(l.PlannedArrival),
+		ActualDeparture:  timestamp(l.ActualDeparture),
+		ActualArrival:    timestamp(l.ActualArrival),
+	}
+}
+
+// legFromProto takes only the planned leg; departures and arrivals are
+// recorded by the use cases.
+func legFromProto(l *shipmentpb.Leg) domain.Leg {
+	if l == nil {
+		return domain.Leg{}
+	}
+
+	return domain.Leg{
+		From:             l.From,
+		To:               l.To,
+		Carrier:          l.Carrier,
+		Mode:             domain.TransportMode(l.Mode),
+		PlannedDeparture: fromTimestamp(l.PlannedDeparture),
+		PlannedArrival:   fromTimestamp(l.PlannedArrival),
+	}
+}
+
+func parcelToProto(p domain.Parcel) *shipmentpb.Parcel {
+	parcel := &shipmentpb.Parcel{Weight: int64(p.Weight)}
+	for _, g := range p.DangerousGoods {
+		parcel.DangerousGoods = append(parcel.DangerousGoods, &shipmentpb.DangerousGood{
+			UnNumber:    g.UNNumber,
+			Class:       int32(g.Class),
+			Description: g.Description,
+		})
+	}
+
+	return parcel
+}
+
+func parcelsFromProto(ps []*shipmentpb.Parcel) []domain.Parcel {
+	parcels := make([]domain.Parcel, 0, len(ps))
+	for _, p := range ps {
+		parcel := domain.Parcel{Weight: int(p.Weight)}
+		for _, g := range p.DangerousGoods {
+			parcel.DangerousGoods = append(parcel.DangerousGoods, domain.DangerousGood{
+				UNNumber:    g.UnNumber,
+				Class:       domain.HazardClass(g.Class),
+				Description: g.Description,
+			})
+		}
+		parcels = append(parcels, parcel)
+	}
+
+	return parcels
+}
+
+func customsToProto(d domain.CustomsDeclaration) *shipmentpb.CustomsDeclaration {
+	customs := &shipmentpb.CustomsDeclaration{
+		Currency: d.Currency,
+		Incoterm: string(d.Incoterm),
+		Exporter: partyToProto(d.Exporter),
+		Importer: partyToProto(d.Importer),
+	}
+	for _, i := range d.Items {
+		customs.Items = append(customs.Items, &shipmentpb.CustomsItem{
+			HsCode:      i.HSCode,
+			Description: i.Description,
+			Quantity:    int64(i.Quantity),
+			UnitValue:   i.UnitValue,
+		})
+	}
+
+	return customs
+}
+
+func customsFromProto(d *shipmentpb.CustomsDeclaration) domain.CustomsDeclaration {
+	if d == nil {
+		return domain.CustomsDeclaration{}
+	}
+
+	customs := domain.CustomsDeclaration{
+		Currency: d.Currency,
+		Incoterm: domain.Incoterm(d.Incoterm),
+		Exporter: partyFromProto(d.Exporter),
+		Importer: partyFromProto(d.Importer),
+	}
+	for _, i := range d.Items {
+		customs.Items = append(customs.Items, domain.CustomsItem{
+			HSCode:      i.HsCode,
+			Description: i.Description,
+			Quantity:    int(i.Quantity),
+			UnitValue:   i.UnitValue,
+		})
+	}
+
+	return customs
+}
+
+func partyToProto(p domain.Party) *shipmentpb.Party {
+	return &shipmentpb.Party{Name: p.Name, Address: p.Address, Country: p.Country, TaxId: p.TaxID}
+}
+
+func partyFromProto(p *shipmentpb.Party) domain.Party {
+	if p == nil {
+		return domain.Party{}
+	}
+	return domain.Party{Name: p.Name, Address: p.Address, Country: p.Country, TaxID: p.TaxId}
+}
+
+// timestamp leaves zero times unset rather than sending year 1.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime()
+}
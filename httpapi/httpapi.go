@@ -0,0 +1,246 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/facucachomeli/workshop-go-testing/config"
+	"github.com/facucachomeli/workshop-go-testing/dangerousgoods"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+)
+
+const AuthorizationHeader = "Authorization"
+
+// maxBodySize caps request bodies, which are small JSON documents.
+const maxBodySize = 1 << 20
+
+type createRequest struct {
+	Origin             string          `json:"origin"`
+	Destination        string          `json:"destination"`
+	ServiceLevel       string          `json:"service_level"`
+	OriginCountry      string          `json:"origin_country"`
+	DestinationCountry string          `json:"destination_country"`
+	Parcels            []domain.Parcel `json:"parcels"`
+}
+
+type cancelRequest struct {
+	Reason domain.CancellationReason `json:"reason"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// caller is who an authenticated request acts as.
+type caller struct {
+	tenant domain.TenantID
+	actor  usecase.Actor
+}
+
+type handler func(http.ResponseWriter, *http.Request, caller)
+
+type api struct {
+	app *config.App
+}
+
+// New exposes the shipment use cases of app over HTTP. Every request
+// authenticates with "Authorization: Bearer <key>", where the key is one of
+// the configured clients, and acts as that client's actor on the shipments
+// of its tenant.
+func New(app *config.App) http.Handler {
+	a := api{app}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/shipments", a.route)
+	mux.HandleFunc("/shipments/", a.route)
+
+	return mux
+}
+
+// route authenticates the request and dispatches /shipments,
+// /shipments/{id} and /shipments/{id}/{action}.
+func (a api) route(w http.ResponseWriter, r *http.Request) {
+	c, ok := a.authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		write(w, http.StatusUnauthorized, errorResponse{"Unauthorized"})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	var h handler
+	method := http.MethodPost
+	switch {
+	case len(parts) == 1:
+		h = a.create
+	case len(parts) == 2:
+		h, method = a.transition(config.Shipments.Get), http.MethodGet
+	case len(parts) == 3:
+		h = a.actions()[parts[2]]
+	}
+	if h == nil {
+		write(w, http.StatusNotFound, errorResponse{"Not found"})
+		return
+	}
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		write(w, http.StatusMethodNotAllowed, errorResponse{"Method not allowed"})
+		return
+	}
+
+	h(w, r, c)
+}
+
+// authenticate finds the configured client whose key the request carries.
+func (a api) authenticate(r *http.Request) (caller, bool) {
+	key, ok := strings.CutPrefix(r.Header.Get(AuthorizationHeader), "Bearer ")
+	if !ok {
+		return caller{}, false
+	}
+	client, ok := a.app.Authenticate(key)
+	if !ok {
+		return caller{}, false
+	}
+
+	return caller{client.Tenant, usecase.Actor{ID: client.Actor, Role: client.Role}}, true
+}
+
+func (a api) actions() map[string]handler {
+	return map[string]handler{
+		"handle":  a.transition(config.Shipments.Handle),
+		"ship":    a.transition(config.Shipments.Ship),
+		"deliver": a.deliver,
+		"cancel":  a.cancel,
+		"return":  a.transition(config.Shipments.InitiateReturn),
+	}
+}
+
+func (a api) create(w http.ResponseWriter, r *http.Request, c caller) {
+	var req createRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	var opts []usecase.CreateOption
+	if req.ServiceLevel != "" {
+		opts = append(opts, usecase.WithServiceLevel(domain.ServiceLevel(req.ServiceLevel)))
+	}
+	if req.OriginCountry != "" || req.DestinationCountry != "" {
+		opts = append(opts, usecase.WithCountries(req.OriginCountry, req.DestinationCountry))
+	}
+	if len(req.Parcels) > 0 {
+		opts = append(opts, usecase.WithParcels(req.Parcels...))
+	}
+
+	s, err := a.app.Shipments(c.tenant).Create(c.actor, req.Origin, req.Destination, opts...)
+	respond(w, http.StatusCreated, s, err)
+}
+
+func (a api) transition(call func(config.Shipments, usecase.Actor, domain.ShipmentID) (domain.Shipment, error)) handler {
+	return func(w http.ResponseWriter, r *http.Request, c caller) {
+		id, ok := idOf(w, r)
+		if !ok {
+			return
+		}
+
+		s, err := call(a.app.Shipments(c.tenant), c.actor, id)
+		respond(w, http.StatusOK, s, err)
+	}
+}
+
+func (a api) deliver(w http.ResponseWriter, r *http.Request, c caller) {
+	id, ok := idOf(w, r)
+	if !ok {
+		return
+	}
+	var proof domain.ProofOfDelivery
+	if !decode(w, r, &proof) {
+		return
+	}
+
+	s, err := a.app.Shipments(c.tenant).Deliver(c.actor, id, proof)
+	respond(w, http.StatusOK, s, err)
+}
+
+func (a api) cancel(w http.ResponseWriter, r *http.Request, c caller) {
+	id, ok := idOf(w, r)
+	if !ok {
+		return
+	}
+	var req cancelRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s, err := a.app.Shipments(c.tenant).Cancel(c.actor, id, req.Reason)
+	respond(w, http.StatusOK, s, err)
+}
+
+func idOf(w http.ResponseWriter, r *http.Request) (domain.ShipmentID, bool) {
+	id, err := strconv.Atoi(strings.Split(strings.Trim(r.URL.Path, "/"), "/")[1])
+	if err != nil || id < 1 {
+		write(w, http.StatusBadRequest, errorResponse{"Invalid shipment ID"})
+		return 0, false
+	}
+	return domain.ShipmentID(id), true
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		write(w, http.StatusRequestEntityTooLarge, errorResponse{"Request body too large"})
+		return false
+	}
+	if err != nil {
+		write(w, http.StatusBadRequest, errorResponse{"Invalid request body"})
+		return false
+	}
+	return true
+}
+
+func respond(w http.ResponseWriter, status int, s domain.Shipment, err error) {
+	if err != nil {
+		write(w, statusOf(err), errorResponse{err.Error()})
+		return
+	}
+	write(w, status, s)
+}
+
+func write(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func statusOf(err error) int {
+	if _, ok := err.(dangerousgoods.Violations); ok {
+		return http.StatusConflict
+	}
+
+	switch err {
+	case usecase.Forbidden:
+		return http.StatusForbidden
+	case usecase.ShipmentDoesNotExist, usecase.ProofOfDeliveryNotFound:
+		return http.StatusNotFound
+	case usecase.CouldNotCreateShipment, usecase.InvalidProofOfDelivery:
+		return http.StatusBadRequest
+	case usecase.ShipmentAlreadyExists,
+		usecase.ShipmentOnHold,
+		usecase.ShipmentCanNotBeHandled,
+		usecase.ShipmentCanNotBeShipped,
+		usecase.ShipmentCanNotBeDelivered,
+		usecase.ShipmentCanNotBeCancelled,
+		usecase.ShipmentCanNotBeReturned,
+		usecase.IncompleteCustomsDeclaration:
+		return http.StatusConflict
+	case usecase.NoCarrierAvailable, usecase.CouldNotBookPickup, usecase.CouldNotRequestRefund:
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
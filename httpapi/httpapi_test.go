@@ -0,0 +1,251 @@
+package httpapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/audit"
+	"github.com/facucachomeli/workshop-go-testing/config"
+	"github.com/facucachomeli/workshop-go-testing/httpapi"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+// newServer serves an audited app with one acme client per role, keyed
+// "acme-<role>", an acme guest allowed nothing, keyed "acme-guest", and a
+// globex admin keyed "globex-admin".
+func newServer(t *testing.T) (*httptest.Server, *config.App) {
+	c := config.Default()
+	c.Carriers = []config.Carrier{{Name: "fast", Type: config.FakeCarrier, Prefix: "FST"}}
+	c.DefaultCarrier = "fast"
+	c.Features.Audit = true
+	for _, role := range []string{"merchant", "warehouse", "driver", "admin"} {
+		c.Clients = append(c.Clients, config.Client{Key: "acme-" + role, Tenant: "acme", Actor: role + "-1", Role: usecase.Role(role)})
+	}
+	c.Clients = append(c.Clients, config.Client{Key: "globex-admin", Tenant: "globex", Actor: "admin-1", Role: usecase.Admin})
+	app, err := config.Build(c)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	// guest is a role the policy knows but grants nothing to.
+	app.Policy[usecase.Role("guest")] = nil
+	app.Config.Clients = append(app.Config.Clients, config.Client{Key: "acme-guest", Tenant: "acme", Actor: "guest-1", Role: usecase.Role("guest")})
+
+	server := httptest.NewServer(httpapi.New(app))
+	t.Cleanup(server.Close)
+
+	return server, app
+}
+
+func call(t *testing.T, server *httptest.Server, method string, path string, role string, body string) (int, map[string]interface{}) {
+	return callAs(t, server, method, path, "acme-"+role, body)
+}
+
+func callAs(t *testing.T, server *httptest.Server, method string, path string, key string, body string) (int, map[string]interface{}) {
+	req, _ := http.NewRequest(method, server.URL+path, bytes.NewBufferString(body))
+	req.Header.Set(httpapi.AuthorizationHeader, "Bearer "+key)
+
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	defer res.Body.Close()
+
+	var decoded map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&decoded)
+
+	return res.StatusCode, decoded
+}
+
+func TestAPI_ShipmentLifecycle(t *testing.T) {
+	server, _ := newServer(t)
+
+	status, body := call(t, server, http.MethodPost, "/shipments", "merchant", `{"origin":"valid origin","destination":"valid destination","service_level":"Express"}`)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "Created", body["state"])
+	assert.Equal(t, "Express", body["service_level"])
+
+	status, body = call(t, server, http.MethodPost, "/shipments/1/handle", "warehouse", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Handled", body["state"])
+
+	status, body = call(t, server, http.MethodPost, "/shipments/1/ship", "warehouse", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "FST00000001", body["tracking_number"])
+
+	proof := `{"recipient_name":"Jane Doe","signature_hash":"d2f1e4b3","delivered_at":"2019-10-01T10:00:00Z"}`
+	status, body = call(t, server, http.MethodPost, "/shipments/1/deliver", "driver", proof)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Delivered", body["state"])
+
+	status, body = call(t, server, http.MethodPost, "/shipments/1/return", "admin", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(1), body["return_of"])
+
+	status, body = call(t, server, http.MethodGet, "/shipments/1", "admin", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Returned", body["state"])
+}
+
+func TestAPI_Cancel(t *testing.T) {
+	server, _ := newServer(t)
+	call(t, server, http.MethodPost, "/shipments", "merchant", `{"origin":"valid origin","destination":"valid destination"}`)
+
+	status, body := call(t, server, http.MethodPost, "/shipments/1/cancel", "merchant", `{"reason":"CustomerRequest"}`)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Cancelled", body["state"])
+}
+
+func TestAPI_Error(t *testing.T) {
+	server, _ := newServer(t)
+	call(t, server, http.MethodPost, "/shipments", "merchant", `{"origin":"valid origin","destination":"valid destination"}`)
+
+	cases := []struct {
+		name           string
+		method         string
+		path           string
+		role           string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Forbidden",
+			method:         http.MethodPost,
+			path:           "/shipments/1/handle",
+			role:           "driver",
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "Forbidden",
+		},
+		{
+			name:           "Forbidden Read",
+			method:         http.MethodGet,
+			path:           "/shipments/1",
+			role:           "guest",
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "Forbidden",
+		},
+		{
+			name:           "Not Found",
+			method:         http.MethodGet,
+			path:           "/shipments/9",
+			role:           "admin",
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Shipment does not exist",
+		},
+		{
+			name:           "Invalid ID",
+			method:         http.MethodPost,
+			path:           "/shipments/abc/handle",
+			role:           "admin",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid shipment ID",
+		},
+		{
+			name:           "Invalid Body",
+			method:         http.MethodPost,
+			path:           "/shipments",
+			role:           "merchant",
+			body:           "{",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request body",
+		},
+		{
+			name:           "Body Too Large",
+			method:         http.MethodPost,
+			path:           "/shipments",
+			role:           "merchant",
+			body:           `{"origin":"` + strings.Repeat("a", 1<<20) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "Request body too large",
+		},
+		{
+			name:           "Invalid Shipment",
+			method:         http.MethodPost,
+			path:           "/shipments",
+			role:           "merchant",
+			body:           `{"origin":"valid origin"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Could not create shipment",
+		},
+		{
+			name:           "Invalid Transition",
+			method:         http.MethodPost,
+			path:           "/shipments/1/ship",
+			role:           "warehouse",
+			expectedStatus: http.StatusConflict,
+			expectedError:  "Shipment can not be shipped",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, body := call(t, server, c.method, c.path, c.role, c.body)
+			assert.Equal(t, c.expectedStatus, status)
+			assert.Equal(t, c.expectedError, body["error"])
+		})
+	}
+}
+
+func TestAPI_Unauthenticated(t *testing.T) {
+	server, _ := newServer(t)
+
+	cases := []struct {
+		name          string
+		authorization string
+	}{
+		{name: "Missing Key", authorization: ""},
+		{name: "Unknown Key", authorization: "Bearer acme-intruder"},
+		{name: "Empty Key", authorization: "Bearer "},
+		{name: "Other Scheme", authorization: "Basic acme-admin"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/shipments", bytes.NewBufferString(`{"origin":"valid origin","destination":"valid destination"}`))
+			if c.authorization != "" {
+				req.Header.Set(httpapi.AuthorizationHeader, c.authorization)
+			}
+
+			res, err := server.Client().Do(req)
+
+			assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+			assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+			assert.Equal(t, "Bearer", res.Header.Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestAPI_Get_Audited(t *testing.T) {
+	server, app := newServer(t)
+	call(t, server, http.MethodPost, "/shipments", "merchant", `{"origin":"valid origin","destination":"valid destination"}`)
+
+	status, _ := call(t, server, http.MethodGet, "/shipments/1", "driver", "")
+	assert.Equal(t, http.StatusOK, status)
+	call(t, server, http.MethodGet, "/shipments/1", "guest", "")
+
+	entries, err := app.Audit.Query(audit.Query{Tenant: "acme", Action: "Get"})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "driver-1", entries[0].Actor)
+		assert.Equal(t, "", entries[0].Error)
+		assert.Equal(t, "guest-1", entries[1].Actor)
+		assert.Equal(t, "Forbidden", entries[1].Error)
+	}
+}
+
+func TestAPI_TenantIsolation(t *testing.T) {
+	server, _ := newServer(t)
+	call(t, server, http.MethodPost, "/shipments", "merchant", `{"origin":"valid origin","destination":"valid destination"}`)
+
+	status, body := callAs(t, server, http.MethodGet, "/shipments/1", "globex-admin", "")
+
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "Shipment does not exist", body["error"])
+	assert.Nil(t, body["id"], "expected no shipment but got %#v", body)
+}
@@ -0,0 +1,34 @@
+// Package golden compares test output against files under testdata. Run the
+// tests with -update to rewrite the files from the current output:
+//
+//	go test ./label -update
+package golden
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// Assert fails the test unless actual matches testdata/<name>.golden.
+func Assert(t testing.TB, name string, actual []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := ioutil.WriteFile(path, actual, 0644); err != nil {
+			t.Fatalf("could not update golden file: %s", err)
+		}
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read golden file: %s", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("%s does not match golden file\nexpected:\n%s\ngot:\n%s", name, expected, actual)
+	}
+}
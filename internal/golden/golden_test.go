@@ -0,0 +1,42 @@
+package golden_test
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/internal/golden"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder stands in for *testing.T so failures can be asserted on instead
+// of failing this test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssert(t *testing.T) {
+	r := &recorder{}
+
+	golden.Assert(r, "hello", []byte("hello\n"))
+
+	assert.Empty(t, r.errors)
+}
+
+func TestAssert_Mismatch(t *testing.T) {
+	if flag.Lookup("update").Value.String() == "true" {
+		t.Skip("would overwrite the golden file with the mismatching output")
+	}
+	r := &recorder{}
+
+	golden.Assert(r, "hello", []byte("bye\n"))
+
+	assert.Equal(t, []string{"hello does not match golden file\nexpected:\nhello\n\ngot:\nbye\n"}, r.errors)
+}
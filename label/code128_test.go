@@ -0,0 +1,63 @@
+package label_test
+
+import (
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/label"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode128_Error(t *testing.T) {
+	for _, data := range []string{"", "tab\t", "ñandú"} {
+		t.Run(data, func(t *testing.T) {
+			symbols, err := label.Code128(data)
+			assert.Equal(t, label.InvalidBarcodeData, err)
+			assert.Nil(t, symbols)
+		})
+	}
+}
+
+func TestCode128_OK(t *testing.T) {
+	symbols, err := label.Code128("PJJ123C")
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, []int{104, 48, 42, 42, 17, 18, 19, 35, 55, 106}, symbols)
+}
+
+func TestCode128Widths_OK(t *testing.T) {
+	data := "SHP000000001"
+	widths, err := label.Code128Widths(data)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	// start, data, checksum and stop symbols are 11 modules wide, plus the
+	// 2 module termination bar.
+	assert.Equal(t, 11*(len(data)+3)+2, total)
+	assert.Equal(t, 6*(len(data)+3)+1, len(widths))
+}
+
+func FuzzCode128Widths(f *testing.F) {
+	for _, data := range []string{"", "tab\t", "ñandú", "PJJ123C", "SHP000000001"} {
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data string) {
+		widths, err := label.Code128Widths(data)
+		if err != nil {
+			assert.Equal(t, label.InvalidBarcodeData, err)
+			assert.Nil(t, widths)
+			return
+		}
+
+		total := 0
+		for _, w := range widths {
+			assert.True(t, w >= 1 && w <= 4, "expected module width between 1 and 4 but got %d", w)
+			total += w
+		}
+		assert.Equal(t, 11*(len(data)+3)+2, total)
+		assert.Equal(t, 6*(len(data)+3)+1, len(widths))
+	})
+}
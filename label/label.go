@@ -0,0 +1,167 @@
+package label
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+var InvalidStateForLabel = errors.New("Shipment is not handled")
+var BarcodeTooLong = errors.New("Barcode does not fit the label")
+
+type Label struct {
+	From           string
+	To             string
+	TrackingNumber string
+}
+
+// New builds the label of a handled or shipped shipment. Shipments not yet
+// booked with a carrier are labelled with a tracking number derived from
+// their ID.
+func New(s domain.Shipment) (Label, error) {
+	if s.State != domain.Handled && s.State != domain.Shipped {
+		return Label{}, InvalidStateForLabel
+	}
+
+	tracking := s.TrackingNumber
+	if tracking == "" {
+		tracking = fmt.Sprintf("SHP%09d", s.ID)
+	}
+
+	return Label{
+		From:           s.Origin,
+		To:             s.Destination,
+		TrackingNumber: tracking,
+	}, nil
+}
+
+const zplWidth = 812
+const zplMargin = 50
+const zplModule = 3
+const zplMinModule = 2
+
+// ZPL renders the label for a 4 inch wide, 203 dpi printer. The barcode
+// module narrows from 3 dots down to 2 so long tracking numbers still fit.
+func (l Label) ZPL() ([]byte, error) {
+	widths, err := Code128Widths(l.TrackingNumber)
+	if err != nil {
+		return nil, err
+	}
+	module := zplModule
+	if fit := (zplWidth - 2*zplMargin) / modules(widths); fit < module {
+		module = fit
+	}
+	if module < zplMinModule {
+		return nil, BarcodeTooLong
+	}
+
+	var b bytes.Buffer
+	b.WriteString("^XA\n")
+	b.WriteString("^CI28\n")
+	fmt.Fprintf(&b, "^FO50,50^A0N,40,40^FH^FDFROM: %s^FS\n", zplEscape(l.From))
+	fmt.Fprintf(&b, "^FO50,110^A0N,40,40^FH^FDTO: %s^FS\n", zplEscape(l.To))
+	fmt.Fprintf(&b, "^FO50,200^BY%d^BCN,200,Y,N,N^FH^FD%s^FS\n", module, zplEscape(l.TrackingNumber))
+	b.WriteString("^XZ\n")
+
+	return b.Bytes(), nil
+}
+
+func zplEscape(s string) string {
+	return strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E").Replace(s)
+}
+
+const pdfWidth = 288
+const pdfHeight = 432
+const pdfMargin = 20
+const pdfModule = 1.5
+
+// pdfMinModule is 0.01 inch, about the narrowest bar label printers
+// reproduce reliably.
+const pdfMinModule = 0.72
+
+// PDF renders a single 4x6 inch page, drawing the barcode as filled
+// rectangles so no barcode font is needed. The module narrows from 1.5pt
+// as needed to keep the barcode within the margins.
+func (l Label) PDF() ([]byte, error) {
+	widths, err := Code128Widths(l.TrackingNumber)
+	if err != nil {
+		return nil, err
+	}
+	module := pdfModule
+	if fit := float64(pdfWidth-2*pdfMargin) / float64(modules(widths)); fit < module {
+		module = fit
+	}
+	if module < pdfMinModule {
+		return nil, BarcodeTooLong
+	}
+
+	var content bytes.Buffer
+	content.WriteString("BT\n/F1 14 Tf\n")
+	fmt.Fprintf(&content, "20 390 Td (FROM: %s) Tj\n", pdfEscape(l.From))
+	fmt.Fprintf(&content, "0 -24 Td (TO: %s) Tj\n", pdfEscape(l.To))
+	fmt.Fprintf(&content, "0 -24 Td (TRACKING: %s) Tj\n", pdfEscape(l.TrackingNumber))
+	content.WriteString("ET\n")
+
+	x := float64(pdfMargin)
+	for i, w := range widths {
+		if i%2 == 0 {
+			fmt.Fprintf(&content, "%.2f 200 %.2f 100 re f\n", x, float64(w)*module)
+		}
+		x += float64(w) * module
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>", pdfWidth, pdfHeight),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return b.Bytes(), nil
+}
+
+// modules is the width of a barcode in modules.
+func modules(widths []int) int {
+	n := 0
+	for _, w := range widths {
+		n += w
+	}
+
+	return n
+}
+
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
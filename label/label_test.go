@@ -0,0 +1,93 @@
+package label_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/internal/golden"
+	"github.com/facucachomeli/workshop-go-testing/label"
+	"github.com/stretchr/testify/assert"
+)
+
+func shippedShipment() domain.Shipment {
+	return domain.Shipment{
+		ID:             domain.ShipmentID(1),
+		State:          domain.Shipped,
+		Origin:         "Warehouse (North)",
+		Destination:    "Av. Siempre Viva 742",
+		TrackingNumber: "FK00000001",
+	}
+}
+
+func TestNew_InvalidStateForLabel(t *testing.T) {
+	for _, state := range []domain.ShipmentState{domain.Created, domain.Delivered, domain.Cancelled} {
+		t.Run(string(state), func(t *testing.T) {
+			l, err := label.New(domain.Shipment{ID: 1, State: state})
+			assert.Equal(t, label.InvalidStateForLabel, err)
+			assert.Zero(t, l)
+		})
+	}
+}
+
+func TestNew_OK(t *testing.T) {
+	l, err := label.New(shippedShipment())
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "FK00000001", l.TrackingNumber)
+
+	l, err = label.New(domain.Shipment{ID: 7, State: domain.Handled, Origin: "a", Destination: "b"})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, "SHP000000007", l.TrackingNumber)
+}
+
+func TestLabel_InvalidBarcodeData(t *testing.T) {
+	l := label.Label{From: "a", To: "b", TrackingNumber: "ñ"}
+
+	_, err := l.ZPL()
+	assert.Equal(t, label.InvalidBarcodeData, err)
+
+	_, err = l.PDF()
+	assert.Equal(t, label.InvalidBarcodeData, err)
+}
+
+func TestLabel_ZPL_Golden(t *testing.T) {
+	l, _ := label.New(shippedShipment())
+	l.To = "Av. Siempre Viva 742 ^ ~_"
+
+	zpl, err := l.ZPL()
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	golden.Assert(t, "label.zpl", zpl)
+}
+
+func TestLabel_PDF_Golden(t *testing.T) {
+	l, _ := label.New(shippedShipment())
+
+	pdf, err := l.PDF()
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	golden.Assert(t, "label.pdf", pdf)
+}
+
+func TestLabel_LongTrackingNumber_Golden(t *testing.T) {
+	l, _ := label.New(shippedShipment())
+	l.TrackingNumber = "JJD0099999999000012345"
+
+	zpl, err := l.ZPL()
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	golden.Assert(t, "label_long.zpl", zpl)
+
+	pdf, err := l.PDF()
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	golden.Assert(t, "label_long.pdf", pdf)
+}
+
+func TestLabel_BarcodeTooLong(t *testing.T) {
+	l := label.Label{From: "a", To: "b", TrackingNumber: strings.Repeat("9", 40)}
+
+	_, err := l.ZPL()
+	assert.Equal(t, label.BarcodeTooLong, err)
+
+	_, err = l.PDF()
+	assert.Equal(t, label.BarcodeTooLong, err)
+}
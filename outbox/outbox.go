@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+// Outbox decouples the use cases from slow event consumers: Publish only
+// queues the shipment and Relay hands queued shipments to the consumer in
+// the background. Shipments that can not be queued or relayed are handed to
+// the overflow function instead, so publishing never blocks a use case.
+type Outbox struct {
+	mu       sync.Mutex
+	queue    chan domain.Shipment
+	closed   bool
+	overflow func(domain.Shipment)
+}
+
+func New(size int, overflow func(domain.Shipment)) *Outbox {
+	return &Outbox{queue: make(chan domain.Shipment, size), overflow: overflow}
+}
+
+// Publish queues s. When the outbox is full or closed s goes to overflow.
+func (o *Outbox) Publish(s domain.Shipment) {
+	o.mu.Lock()
+	queued := false
+	if !o.closed {
+		select {
+		case o.queue <- s:
+			queued = true
+		default:
+		}
+	}
+	o.mu.Unlock()
+
+	if !queued {
+		o.overflow(s)
+	}
+}
+
+// Close stops accepting shipments. Relay returns once the ones already
+// queued have been delivered.
+func (o *Outbox) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.closed {
+		o.closed = true
+		close(o.queue)
+	}
+}
+
+// Relay delivers queued shipments until the outbox is closed and drained,
+// or ctx is done. deliver gets ctx so it can give up on a slow consumer.
+// The shipments still queued when ctx is done go to overflow.
+func (o *Outbox) Relay(ctx context.Context, deliver func(context.Context, domain.Shipment)) {
+	for {
+		if ctx.Err() != nil {
+			o.spill()
+			return
+		}
+
+		select {
+		case s, ok := <-o.queue:
+			if !ok {
+				return
+			}
+			deliver(ctx, s)
+		case <-ctx.Done():
+			o.spill()
+			return
+		}
+	}
+}
+
+func (o *Outbox) spill() {
+	for {
+		select {
+		case s, ok := <-o.queue:
+			if !ok {
+				return
+			}
+			o.overflow(s)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package outbox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/outbox"
+	"github.com/stretchr/testify/assert"
+)
+
+// overflowed collects the shipments an outbox hands to its overflow.
+type overflowed []domain.ShipmentID
+
+func (o *overflowed) add(s domain.Shipment) {
+	*o = append(*o, s.ID)
+}
+
+func TestOutbox_Relay_DrainsOnClose(t *testing.T) {
+	var dropped overflowed
+	o := outbox.New(10, dropped.add)
+	for id := domain.ShipmentID(1); id <= 3; id++ {
+		o.Publish(domain.Shipment{ID: id})
+	}
+	o.Close()
+	o.Publish(domain.Shipment{ID: 4})
+
+	var delivered []domain.ShipmentID
+	o.Relay(context.Background(), func(_ context.Context, s domain.Shipment) {
+		delivered = append(delivered, s.ID)
+	})
+
+	assert.Equal(t, []domain.ShipmentID{1, 2, 3}, delivered)
+	assert.Equal(t, overflowed{4}, dropped)
+}
+
+func TestOutbox_Relay_StopsOnContext(t *testing.T) {
+	var dropped overflowed
+	o := outbox.New(2, dropped.add)
+	o.Publish(domain.Shipment{ID: 1})
+	o.Publish(domain.Shipment{ID: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		o.Relay(ctx, func(_ context.Context, s domain.Shipment) {
+			t.Errorf("expected shipment %d not to be delivered after the context was cancelled", s.ID)
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected relay to stop after the context was cancelled")
+	}
+	assert.Equal(t, overflowed{1, 2}, dropped)
+}
+
+func TestOutbox_Publish_OverflowsWhileFull(t *testing.T) {
+	var dropped overflowed
+	o := outbox.New(1, dropped.add)
+	o.Publish(domain.Shipment{ID: 1})
+
+	published := make(chan struct{})
+	go func() {
+		o.Publish(domain.Shipment{ID: 2})
+		close(published)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatalf("expected publish not to block while the outbox is full")
+	}
+	o.Close()
+
+	var delivered []domain.ShipmentID
+	o.Relay(context.Background(), func(_ context.Context, s domain.Shipment) {
+		delivered = append(delivered, s.ID)
+	})
+
+	assert.Equal(t, []domain.ShipmentID{1}, delivered)
+	assert.Equal(t, overflowed{2}, dropped)
+}
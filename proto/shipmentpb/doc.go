@@ -0,0 +1,14 @@
+// Package shipmentpb is generated from proto/shipment.proto.
+//
+// The checked-in files were not produced by protoc. The schema was compiled
+// with github.com/bufbuild/protocompile v0.14.1 and the resulting
+// CodeGeneratorRequest was fed to protoc-gen-go v1.35.1 and
+// protoc-gen-go-grpc v1.5.1, which is why the headers list the protoc
+// version as unknown. Running protoc with the same plugin versions from the
+// repository root gives the same code apart from that header line:
+//
+//	protoc -I proto --go_out=. --go-grpc_out=. \
+//		--go_opt=module=github.com/facucachomeli/workshop-go-testing \
+//		--go-grpc_opt=module=github.com/facucachomeli/workshop-go-testing \
+//		shipment.proto
+package shipmentpb
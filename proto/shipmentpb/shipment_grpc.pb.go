@@ -0,0 +1,697 @@
+// Wire contract for the shipment use cases.
+//
+// Callers authenticate with an "authorization: Bearer <key>" metadata entry
+// naming one of the configured API clients, which fixes the tenant, actor
+// and role every call acts as.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: shipment.proto
+
+package shipmentpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ShipmentService_GetShipment_FullMethodName           = "/shipment.v1.ShipmentService/GetShipment"
+	ShipmentService_CreateShipment_FullMethodName        = "/shipment.v1.ShipmentService/CreateShipment"
+	ShipmentService_HandleShipment_FullMethodName        = "/shipment.v1.ShipmentService/HandleShipment"
+	ShipmentService_ShipShipment_FullMethodName          = "/shipment.v1.ShipmentService/ShipShipment"
+	ShipmentService_DeliverShipment_FullMethodName       = "/shipment.v1.ShipmentService/DeliverShipment"
+	ShipmentService_CancelShipment_FullMethodName        = "/shipment.v1.ShipmentService/CancelShipment"
+	ShipmentService_InitiateReturn_FullMethodName        = "/shipment.v1.ShipmentService/InitiateReturn"
+	ShipmentService_GetProofOfDelivery_FullMethodName    = "/shipment.v1.ShipmentService/GetProofOfDelivery"
+	ShipmentService_ReportDeliveryAttempt_FullMethodName = "/shipment.v1.ShipmentService/ReportDeliveryAttempt"
+	ShipmentService_DeclareCustoms_FullMethodName        = "/shipment.v1.ShipmentService/DeclareCustoms"
+	ShipmentService_PlanLeg_FullMethodName               = "/shipment.v1.ShipmentService/PlanLeg"
+	ShipmentService_DepartLeg_FullMethodName             = "/shipment.v1.ShipmentService/DepartLeg"
+	ShipmentService_ArriveLeg_FullMethodName             = "/shipment.v1.ShipmentService/ArriveLeg"
+	ShipmentService_PlaceHold_FullMethodName             = "/shipment.v1.ShipmentService/PlaceHold"
+	ShipmentService_ReleaseHold_FullMethodName           = "/shipment.v1.ShipmentService/ReleaseHold"
+	ShipmentService_ListOnHold_FullMethodName            = "/shipment.v1.ShipmentService/ListOnHold"
+)
+
+// ShipmentServiceClient is the client API for ShipmentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ShipmentServiceClient interface {
+	GetShipment(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*Shipment, error)
+	CreateShipment(ctx context.Context, in *CreateShipmentRequest, opts ...grpc.CallOption) (*Shipment, error)
+	HandleShipment(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*Shipment, error)
+	ShipShipment(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*Shipment, error)
+	DeliverShipment(ctx context.Context, in *DeliverShipmentRequest, opts ...grpc.CallOption) (*Shipment, error)
+	CancelShipment(ctx context.Context, in *CancelShipmentRequest, opts ...grpc.CallOption) (*Shipment, error)
+	InitiateReturn(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*Shipment, error)
+	GetProofOfDelivery(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*ProofOfDelivery, error)
+	ReportDeliveryAttempt(ctx context.Context, in *ReportDeliveryAttemptRequest, opts ...grpc.CallOption) (*Shipment, error)
+	DeclareCustoms(ctx context.Context, in *DeclareCustomsRequest, opts ...grpc.CallOption) (*Shipment, error)
+	PlanLeg(ctx context.Context, in *PlanLegRequest, opts ...grpc.CallOption) (*Shipment, error)
+	DepartLeg(ctx context.Context, in *LegRequest, opts ...grpc.CallOption) (*Shipment, error)
+	ArriveLeg(ctx context.Context, in *LegRequest, opts ...grpc.CallOption) (*Shipment, error)
+	PlaceHold(ctx context.Context, in *PlaceHoldRequest, opts ...grpc.CallOption) (*Shipment, error)
+	ReleaseHold(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*Shipment, error)
+	ListOnHold(ctx context.Context, in *ListOnHoldRequest, opts ...grpc.CallOption) (*ListOnHoldResponse, error)
+}
+
+type shipmentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShipmentServiceClient(cc grpc.ClientConnInterface) ShipmentServiceClient {
+	return &shipmentServiceClient{cc}
+}
+
+func (c *shipmentServiceClient) GetShipment(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_GetShipment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) CreateShipment(ctx context.Context, in *CreateShipmentRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_CreateShipment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) HandleShipment(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_HandleShipment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) ShipShipment(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_ShipShipment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) DeliverShipment(ctx context.Context, in *DeliverShipmentRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_DeliverShipment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) CancelShipment(ctx context.Context, in *CancelShipmentRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_CancelShipment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) InitiateReturn(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_InitiateReturn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) GetProofOfDelivery(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*ProofOfDelivery, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProofOfDelivery)
+	err := c.cc.Invoke(ctx, ShipmentService_GetProofOfDelivery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) ReportDeliveryAttempt(ctx context.Context, in *ReportDeliveryAttemptRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_ReportDeliveryAttempt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) DeclareCustoms(ctx context.Context, in *DeclareCustomsRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_DeclareCustoms_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) PlanLeg(ctx context.Context, in *PlanLegRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_PlanLeg_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) DepartLeg(ctx context.Context, in *LegRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_DepartLeg_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) ArriveLeg(ctx context.Context, in *LegRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_ArriveLeg_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) PlaceHold(ctx context.Context, in *PlaceHoldRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_PlaceHold_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) ReleaseHold(ctx context.Context, in *ShipmentRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, ShipmentService_ReleaseHold_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shipmentServiceClient) ListOnHold(ctx context.Context, in *ListOnHoldRequest, opts ...grpc.CallOption) (*ListOnHoldResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOnHoldResponse)
+	err := c.cc.Invoke(ctx, ShipmentService_ListOnHold_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ShipmentServiceServer is the server API for ShipmentService service.
+// All implementations must embed UnimplementedShipmentServiceServer
+// for forward compatibility.
+type ShipmentServiceServer interface {
+	GetShipment(context.Context, *ShipmentRequest) (*Shipment, error)
+	CreateShipment(context.Context, *CreateShipmentRequest) (*Shipment, error)
+	HandleShipment(context.Context, *ShipmentRequest) (*Shipment, error)
+	ShipShipment(context.Context, *ShipmentRequest) (*Shipment, error)
+	DeliverShipment(context.Context, *DeliverShipmentRequest) (*Shipment, error)
+	CancelShipment(context.Context, *CancelShipmentRequest) (*Shipment, error)
+	InitiateReturn(context.Context, *ShipmentRequest) (*Shipment, error)
+	GetProofOfDelivery(context.Context, *ShipmentRequest) (*ProofOfDelivery, error)
+	ReportDeliveryAttempt(context.Context, *ReportDeliveryAttemptRequest) (*Shipment, error)
+	DeclareCustoms(context.Context, *DeclareCustomsRequest) (*Shipment, error)
+	PlanLeg(context.Context, *PlanLegRequest) (*Shipment, error)
+	DepartLeg(context.Context, *LegRequest) (*Shipment, error)
+	ArriveLeg(context.Context, *LegRequest) (*Shipment, error)
+	PlaceHold(context.Context, *PlaceHoldRequest) (*Shipment, error)
+	ReleaseHold(context.Context, *ShipmentRequest) (*Shipment, error)
+	ListOnHold(context.Context, *ListOnHoldRequest) (*ListOnHoldResponse, error)
+	mustEmbedUnimplementedShipmentServiceServer()
+}
+
+// UnimplementedShipmentServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedShipmentServiceServer struct{}
+
+func (UnimplementedShipmentServiceServer) GetShipment(context.Context, *ShipmentRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShipment not implemented")
+}
+func (UnimplementedShipmentServiceServer) CreateShipment(context.Context, *CreateShipmentRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateShipment not implemented")
+}
+func (UnimplementedShipmentServiceServer) HandleShipment(context.Context, *ShipmentRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleShipment not implemented")
+}
+func (UnimplementedShipmentServiceServer) ShipShipment(context.Context, *ShipmentRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShipShipment not implemented")
+}
+func (UnimplementedShipmentServiceServer) DeliverShipment(context.Context, *DeliverShipmentRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeliverShipment not implemented")
+}
+func (UnimplementedShipmentServiceServer) CancelShipment(context.Context, *CancelShipmentRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelShipment not implemented")
+}
+func (UnimplementedShipmentServiceServer) InitiateReturn(context.Context, *ShipmentRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InitiateReturn not implemented")
+}
+func (UnimplementedShipmentServiceServer) GetProofOfDelivery(context.Context, *ShipmentRequest) (*ProofOfDelivery, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProofOfDelivery not implemented")
+}
+func (UnimplementedShipmentServiceServer) ReportDeliveryAttempt(context.Context, *ReportDeliveryAttemptRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportDeliveryAttempt not implemented")
+}
+func (UnimplementedShipmentServiceServer) DeclareCustoms(context.Context, *DeclareCustomsRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeclareCustoms not implemented")
+}
+func (UnimplementedShipmentServiceServer) PlanLeg(context.Context, *PlanLegRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlanLeg not implemented")
+}
+func (UnimplementedShipmentServiceServer) DepartLeg(context.Context, *LegRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DepartLeg not implemented")
+}
+func (UnimplementedShipmentServiceServer) ArriveLeg(context.Context, *LegRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArriveLeg not implemented")
+}
+func (UnimplementedShipmentServiceServer) PlaceHold(context.Context, *PlaceHoldRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlaceHold not implemented")
+}
+func (UnimplementedShipmentServiceServer) ReleaseHold(context.Context, *ShipmentRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseHold not implemented")
+}
+func (UnimplementedShipmentServiceServer) ListOnHold(context.Context, *ListOnHoldRequest) (*ListOnHoldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOnHold not implemented")
+}
+func (UnimplementedShipmentServiceServer) mustEmbedUnimplementedShipmentServiceServer() {}
+func (UnimplementedShipmentServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeShipmentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ShipmentServiceServer will
+// result in compilation errors.
+type UnsafeShipmentServiceServer interface {
+	mustEmbedUnimplementedShipmentServiceServer()
+}
+
+func RegisterShipmentServiceServer(s grpc.ServiceRegistrar, srv ShipmentServiceServer) {
+	// If the following call pancis, it indicates UnimplementedShipmentServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ShipmentService_ServiceDesc, srv)
+}
+
+func _ShipmentService_GetShipment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).GetShipment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_GetShipment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).GetShipment(ctx, req.(*ShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_CreateShipment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).CreateShipment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_CreateShipment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).CreateShipment(ctx, req.(*CreateShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_HandleShipment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).HandleShipment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_HandleShipment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).HandleShipment(ctx, req.(*ShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_ShipShipment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).ShipShipment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_ShipShipment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).ShipShipment(ctx, req.(*ShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_DeliverShipment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeliverShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).DeliverShipment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_DeliverShipment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).DeliverShipment(ctx, req.(*DeliverShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_CancelShipment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).CancelShipment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_CancelShipment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).CancelShipment(ctx, req.(*CancelShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_InitiateReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).InitiateReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_InitiateReturn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).InitiateReturn(ctx, req.(*ShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_GetProofOfDelivery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).GetProofOfDelivery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_GetProofOfDelivery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).GetProofOfDelivery(ctx, req.(*ShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_ReportDeliveryAttempt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportDeliveryAttemptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).ReportDeliveryAttempt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_ReportDeliveryAttempt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).ReportDeliveryAttempt(ctx, req.(*ReportDeliveryAttemptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_DeclareCustoms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeclareCustomsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).DeclareCustoms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_DeclareCustoms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).DeclareCustoms(ctx, req.(*DeclareCustomsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_PlanLeg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlanLegRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).PlanLeg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_PlanLeg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).PlanLeg(ctx, req.(*PlanLegRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_DepartLeg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LegRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).DepartLeg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_DepartLeg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).DepartLeg(ctx, req.(*LegRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_ArriveLeg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LegRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).ArriveLeg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_ArriveLeg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).ArriveLeg(ctx, req.(*LegRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_PlaceHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaceHoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).PlaceHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_PlaceHold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).PlaceHold(ctx, req.(*PlaceHoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_ReleaseHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).ReleaseHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_ReleaseHold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).ReleaseHold(ctx, req.(*ShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShipmentService_ListOnHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOnHoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShipmentServiceServer).ListOnHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShipmentService_ListOnHold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShipmentServiceServer).ListOnHold(ctx, req.(*ListOnHoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ShipmentService_ServiceDesc is the grpc.ServiceDesc for ShipmentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ShipmentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "shipment.v1.ShipmentService",
+	HandlerType: (*ShipmentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetShipment",
+			Handler:    _ShipmentService_GetShipment_Handler,
+		},
+		{
+			MethodName: "CreateShipment",
+			Handler:    _ShipmentService_CreateShipment_Handler,
+		},
+		{
+			MethodName: "HandleShipment",
+			Handler:    _ShipmentService_HandleShipment_Handler,
+		},
+		{
+			MethodName: "ShipShipment",
+			Handler:    _ShipmentService_ShipShipment_Handler,
+		},
+		{
+			MethodName: "DeliverShipment",
+			Handler:    _ShipmentService_DeliverShipment_Handler,
+		},
+		{
+			MethodName: "CancelShipment",
+			Handler:    _ShipmentService_CancelShipment_Handler,
+		},
+		{
+			MethodName: "InitiateReturn",
+			Handler:    _ShipmentService_InitiateReturn_Handler,
+		},
+		{
+			MethodName: "GetProofOfDelivery",
+			Handler:    _ShipmentService_GetProofOfDelivery_Handler,
+		},
+		{
+			MethodName: "ReportDeliveryAttempt",
+			Handler:    _ShipmentService_ReportDeliveryAttempt_Handler,
+		},
+		{
+			MethodName: "DeclareCustoms",
+			Handler:    _ShipmentService_DeclareCustoms_Handler,
+		},
+		{
+			MethodName: "PlanLeg",
+			Handler:    _ShipmentService_PlanLeg_Handler,
+		},
+		{
+			MethodName: "DepartLeg",
+			Handler:    _ShipmentService_DepartLeg_Handler,
+		},
+		{
+			MethodName: "ArriveLeg",
+			Handler:    _ShipmentService_ArriveLeg_Handler,
+		},
+		{
+			MethodName: "PlaceHold",
+			Handler:    _ShipmentService_PlaceHold_Handler,
+		},
+		{
+			MethodName: "ReleaseHold",
+			Handler:    _ShipmentService_ReleaseHold_Handler,
+		},
+		{
+			MethodName: "ListOnHold",
+			Handler:    _ShipmentService_ListOnHold_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "shipment.proto",
+}
@@ -0,0 +1,304 @@
+// Package simulation runs randomized concurrent workloads against the
+// shipment use cases backed by a real repository, and checks that the
+// outcome could have been produced by running the successful calls one at a
+// time. Run its tests with -race.
+package simulation
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/carrier"
+	"github.com/facucachomeli/workshop-go-testing/carrier/fake"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/storage"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+)
+
+type Workload struct {
+	Seed       int64
+	Workers    int
+	Operations int // per worker
+	// IDSpace makes the sequence hand out random IDs between 1 and IDSpace
+	// instead of unique ones, so concurrent creations collide. Zero keeps
+	// the repository's own sequence.
+	IDSpace int
+}
+
+type Operation string
+
+var Create = Operation("Create")
+var Handle = Operation("Handle")
+var Ship = Operation("Ship")
+var Deliver = Operation("Deliver")
+var Cancel = Operation("Cancel")
+
+var operations = []Operation{Create, Handle, Ship, Deliver, Cancel}
+
+// Call is one use case invocation made by a worker and what it returned.
+type Call struct {
+	Worker    int
+	Operation Operation
+	ID        domain.ShipmentID
+	Shipment  domain.Shipment
+	Err       error
+}
+
+// Save is a write the repository accepted.
+type Save struct {
+	ID      domain.ShipmentID
+	Version int
+	State   domain.ShipmentState
+}
+
+type Result struct {
+	Workload Workload
+	Issued   []domain.ShipmentID
+	Calls    []Call
+	Saves    []Save
+	Stored   []domain.Shipment
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	result  Result
+	created []domain.ShipmentID
+}
+
+func (r *recorder) issue(id domain.ShipmentID) domain.ShipmentID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.Issued = append(r.result.Issued, id)
+	return id
+}
+
+func (r *recorder) saved(s domain.Shipment) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.Saves = append(r.result.Saves, Save{s.ID, s.Version, s.State})
+}
+
+func (r *recorder) called(c Call) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.Calls = append(r.result.Calls, c)
+	if c.Operation == Create && c.Err == nil {
+		r.created = append(r.created, c.Shipment.ID)
+	}
+}
+
+// target picks a shipment a worker acts on, favouring the ones already
+// created so most calls reach the domain.
+func (r *recorder) target(rnd *rand.Rand) domain.ShipmentID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.created) == 0 || rnd.Intn(10) == 0 {
+		return domain.ShipmentID(1 + rnd.Intn(len(r.created)+1))
+	}
+	return r.created[rnd.Intn(len(r.created))]
+}
+
+var proof = domain.ProofOfDelivery{
+	RecipientName: "Jane Doe",
+	SignatureHash: "d2f1e4b3",
+	DeliveredAt:   time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC),
+}
+
+func Run(w Workload) (Result, error) {
+	store := storage.NewMemory().ForTenant("simulation")
+	carriers := carrier.NewRegistry()
+	if err := carriers.Register("fake", fake.New("SIM")); err != nil {
+		return Result{}, err
+	}
+	if err := carriers.Default("fake"); err != nil {
+		return Result{}, err
+	}
+
+	rec := &recorder{result: Result{Workload: w}}
+	collisions := rand.New(rand.NewSource(w.Seed))
+	var collisionsMu sync.Mutex
+	sequence := func() domain.ShipmentID {
+		if w.IDSpace <= 0 {
+			return rec.issue(store.Next())
+		}
+		collisionsMu.Lock()
+		defer collisionsMu.Unlock()
+		return rec.issue(domain.ShipmentID(1 + collisions.Intn(w.IDSpace)))
+	}
+	save := func(s *domain.Shipment) error {
+		if err := store.Save(s); err != nil {
+			return err
+		}
+		rec.saved(*s)
+		return nil
+	}
+	now := func() time.Time { return proof.DeliveredAt }
+	uc := usecase.NewShipmentUseCase(save, store, sequence).
+		WithClock(now).
+		WithCarriers(carriers)
+	admin := usecase.Actor{ID: "simulation", Role: usecase.Admin}
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < w.Workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(w.Seed + int64(worker) + 1))
+			for n := 0; n < w.Operations; n++ {
+				c := Call{Worker: worker, Operation: operations[rnd.Intn(len(operations))]}
+				switch c.Operation {
+				case Create:
+					c.Shipment, c.Err = uc.Create(admin, "valid origin", "valid destination")
+					c.ID = c.Shipment.ID
+				case Handle:
+					c.ID = rec.target(rnd)
+					c.Shipment, c.Err = uc.Handle(admin, c.ID)
+				case Ship:
+					c.ID = rec.target(rnd)
+					c.Shipment, c.Err = uc.Ship(admin, c.ID)
+				case Deliver:
+					c.ID = rec.target(rnd)
+					c.Shipment, c.Err = uc.Deliver(admin, c.ID, proof)
+				case Cancel:
+					c.ID = rec.target(rnd)
+					c.Shipment, c.Err = uc.Cancel(admin, c.ID, domain.CustomerRequest)
+				}
+				rec.called(c)
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	stored, err := store.List()
+	if err != nil {
+		return Result{}, err
+	}
+	rec.result.Stored = stored
+
+	return rec.result, nil
+}
+
+// Check returns every invariant the run broke, or nothing if the history is
+// consistent with some sequential execution of the successful calls.
+func (r Result) Check() []string {
+	var violations []string
+	violations = append(violations, r.uniqueIssuedIDs()...)
+	violations = append(violations, r.noDuplicateCreation()...)
+	violations = append(violations, r.noLostUpdates()...)
+	violations = append(violations, r.callsWereSaved()...)
+	return violations
+}
+
+func (r Result) uniqueIssuedIDs() []string {
+	if r.Workload.IDSpace > 0 {
+		return nil
+	}
+
+	var violations []string
+	seen := map[domain.ShipmentID]bool{}
+	for _, id := range r.Issued {
+		if seen[id] {
+			violations = append(violations, fmt.Sprintf("sequence issued ID %d twice", id))
+		}
+		seen[id] = true
+	}
+	return violations
+}
+
+func (r Result) noDuplicateCreation() []string {
+	var violations []string
+	created := map[domain.ShipmentID]int{}
+	for _, c := range r.Calls {
+		if c.Operation == Create && c.Err == nil {
+			created[c.ID]++
+		}
+	}
+	for id, n := range created {
+		if n > 1 {
+			violations = append(violations, fmt.Sprintf("shipment %d was created %d times", id, n))
+		}
+	}
+	if len(created) != len(r.Stored) {
+		violations = append(violations, fmt.Sprintf("%d shipments were created but %d are stored", len(created), len(r.Stored)))
+	}
+	return violations
+}
+
+// noLostUpdates replays the accepted writes of every shipment in version
+// order: each version must have been written exactly once, through a legal
+// transition, and the last one must be what the repository holds.
+func (r Result) noLostUpdates() []string {
+	var violations []string
+	saves := map[domain.ShipmentID][]Save{}
+	for _, s := range r.Saves {
+		saves[s.ID] = append(saves[s.ID], s)
+	}
+
+	for _, stored := range r.Stored {
+		history := saves[stored.ID]
+		sort.Slice(history, func(i, j int) bool { return history[i].Version < history[j].Version })
+
+		previous := domain.ShipmentState("")
+		for i, s := range history {
+			if s.Version != i+1 {
+				violations = append(violations, fmt.Sprintf("shipment %d has writes %v, expected one per version", stored.ID, history))
+				break
+			}
+			if !legal(previous, s.State) {
+				violations = append(violations, fmt.Sprintf("shipment %d moved from '%s' to '%s' at version %d", stored.ID, previous, s.State, s.Version))
+			}
+			previous = s.State
+		}
+		if stored.Version != len(history) || stored.State != previous {
+			violations = append(violations, fmt.Sprintf("shipment %d is stored %s at version %d but its last write was %s at version %d", stored.ID, stored.State, stored.Version, previous, len(history)))
+		}
+	}
+	return violations
+}
+
+func (r Result) callsWereSaved() []string {
+	var violations []string
+	saved := map[Save]bool{}
+	for _, s := range r.Saves {
+		saved[s] = true
+	}
+	// Delivering twice succeeds without writing, but still returns a
+	// version that was saved by the first delivery.
+	for _, c := range r.Calls {
+		if c.Err != nil {
+			continue
+		}
+		if !saved[Save{c.ID, c.Shipment.Version, c.Shipment.State}] {
+			violations = append(violations, fmt.Sprintf("worker %d got %s %d at version %d from %s but it was never saved", c.Worker, c.Shipment.State, c.ID, c.Shipment.Version, c.Operation))
+		}
+	}
+	return violations
+}
+
+// transitions is the state machine the simulation holds the use cases to,
+// written out here rather than taken from the code under test.
+var transitions = map[domain.ShipmentState][]domain.ShipmentState{
+	"":                    {domain.Created},
+	domain.Created:        {domain.Handled, domain.Cancelled},
+	domain.Handled:        {domain.Shipped, domain.Cancelled},
+	domain.Shipped:        {domain.Delivered, domain.DeliveryFailed},
+	domain.Delivered:      {domain.Returned},
+	domain.DeliveryFailed: {domain.Returned},
+}
+
+// legal also accepts writes that keep the state, such as recording that the
+// refund of a cancellation was requested.
+func legal(from domain.ShipmentState, to domain.ShipmentState) bool {
+	if from != "" && from == to {
+		return true
+	}
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package simulation_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/simulation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		name     string
+		workload simulation.Workload
+	}{
+		{
+			name:     "Unique IDs",
+			workload: simulation.Workload{Workers: 8, Operations: 200},
+		},
+		{
+			name:     "Colliding IDs",
+			workload: simulation.Workload{Workers: 8, Operations: 200, IDSpace: 20},
+		},
+		{
+			name:     "Single Worker",
+			workload: simulation.Workload{Workers: 1, Operations: 500},
+		},
+	}
+
+	for _, c := range cases {
+		for seed := int64(1); seed <= 5; seed++ {
+			c.workload.Seed = seed
+			t.Run(fmt.Sprintf("%s/%d", c.name, seed), func(t *testing.T) {
+				result, err := simulation.Run(c.workload)
+				assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+				assert.NotEmpty(t, result.Stored)
+				for _, v := range result.Check() {
+					t.Errorf("seed %d: %s", c.workload.Seed, v)
+				}
+			})
+		}
+	}
+}
+
+func TestResult_Check_LostUpdate(t *testing.T) {
+	result := simulation.Result{
+		Calls: []simulation.Call{
+			{Operation: simulation.Create, ID: 1, Shipment: domain.Shipment{ID: 1, Version: 1, State: domain.Created}},
+			{Operation: simulation.Handle, ID: 1, Shipment: domain.Shipment{ID: 1, Version: 2, State: domain.Handled}},
+			{Operation: simulation.Cancel, ID: 1, Shipment: domain.Shipment{ID: 1, Version: 2, State: domain.Cancelled}},
+		},
+		Saves: []simulation.Save{
+			{ID: 1, Version: 1, State: domain.Created},
+			{ID: 1, Version: 2, State: domain.Handled},
+			{ID: 1, Version: 2, State: domain.Cancelled},
+		},
+		Stored: []domain.Shipment{{ID: 1, Version: 2, State: domain.Cancelled}},
+	}
+
+	assert.NotEmpty(t, result.Check())
+}
+
+func TestResult_Check_DuplicateCreation(t *testing.T) {
+	result := simulation.Result{
+		Workload: simulation.Workload{IDSpace: 1},
+		Calls: []simulation.Call{
+			{Operation: simulation.Create, ID: 1, Shipment: domain.Shipment{ID: 1, Version: 1, State: domain.Created}},
+			{Operation: simulation.Create, ID: 1, Shipment: domain.Shipment{ID: 1, Version: 1, State: domain.Created}},
+		},
+		Saves:  []simulation.Save{{ID: 1, Version: 1, State: domain.Created}},
+		Stored: []domain.Shipment{{ID: 1, Version: 1, State: domain.Created}},
+	}
+
+	assert.Equal(t, []string{"shipment 1 was created 2 times"}, result.Check())
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+// OpenFile loads every shipment stored at path into memory and writes the
+// whole snapshot back after each save, replacing the file atomically.
+func OpenFile(path string) (*Memory, error) {
+	m := NewMemory()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		var shipments []domain.Shipment
+		if err := json.Unmarshal(data, &shipments); err != nil {
+			return nil, err
+		}
+		for _, s := range shipments {
+			m.shipments[key{s.Tenant, s.ID}] = s
+			if s.ID > m.sequences[s.Tenant] {
+				m.sequences[s.Tenant] = s.ID
+			}
+		}
+	}
+
+	m.persist = func(all map[key]domain.Shipment) error {
+		return writeSnapshot(path, all)
+	}
+
+	return m, nil
+}
+
+func writeSnapshot(path string, all map[key]domain.Shipment) error {
+	shipments := make([]domain.Shipment, 0, len(all))
+	for _, s := range all {
+		shipments = append(shipments, s)
+	}
+	sort.Slice(shipments, func(i, j int) bool {
+		if shipments[i].Tenant != shipments[j].Tenant {
+			return shipments[i].Tenant < shipments[j].Tenant
+		}
+		return shipments[i].ID < shipments[j].ID
+	})
+
+	data, err := json.Marshal(shipments)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
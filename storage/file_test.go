@@ -0,0 +1,96 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/storage"
+	"github.com/facucachomeli/workshop-go-testing/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+func TestFile_Contract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Repository {
+		m, err := storage.OpenFile(filepath.Join(tempDir(t), "shipments.json"))
+		if err != nil {
+			t.Fatalf("expected error to be nil but got '%s'", err)
+		}
+		return m.ForTenant("acme")
+	})
+}
+
+func TestFile_Reopen(t *testing.T) {
+	path := filepath.Join(tempDir(t), "shipments.json")
+	m, _ := storage.OpenFile(path)
+	acme := m.ForTenant("acme")
+	s := domain.Shipment{ID: acme.Next(), State: domain.Created, Origin: "valid origin", Destination: "valid destination"}
+	assert.Nil(t, acme.Save(&s))
+
+	reopened, err := storage.OpenFile(path)
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	found, _ := reopened.ForTenant("acme").GetByID(s.ID)
+	assert.Equal(t, s, found)
+	other, _ := reopened.ForTenant("globex").GetByID(s.ID)
+	assert.True(t, other.IsNil(), "expected shipment to be nil but got %#v", other)
+	assert.Equal(t, domain.ShipmentID(2), reopened.ForTenant("acme").Next())
+}
+
+func TestFile_Open_Corrupted(t *testing.T) {
+	path := filepath.Join(tempDir(t), "shipments.json")
+	ioutil.WriteFile(path, []byte("not json"), 0600)
+
+	m, err := storage.OpenFile(path)
+
+	assert.NotNilf(t, err, "expected error but found none")
+	assert.Nil(t, m)
+}
+
+func FuzzOpenFile(f *testing.F) {
+	f.Add([]byte(`[{"id":1,"tenant":"acme","version":1,"state":"Created","origin":"valid origin","destination":"valid destination"}]`))
+	f.Add([]byte(`[]`))
+	f.Add([]byte(`not json`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		path := filepath.Join(tempDir(t), "shipments.json")
+		ioutil.WriteFile(path, data, 0600)
+
+		m, err := storage.OpenFile(path)
+		if err != nil {
+			return
+		}
+
+		s := domain.Shipment{ID: m.ForTenant("acme").Next(), State: domain.Created}
+		assert.Nil(t, m.ForTenant("acme").Save(&s))
+		reopened, err := storage.OpenFile(path)
+		assert.Nilf(t, err, "expected snapshot written by the store to open but got '%s'", err)
+		found, _ := reopened.ForTenant("acme").GetByID(s.ID)
+		assert.Equal(t, s.Version, found.Version)
+	})
+}
+
+func TestFile_Flush(t *testing.T) {
+	path := filepath.Join(tempDir(t), "shipments.json")
+	m, _ := storage.OpenFile(path)
+	m.ForTenant("acme").Save(&domain.Shipment{ID: 1, State: domain.Created})
+	os.Remove(path)
+
+	err := m.Flush()
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	reopened, _ := storage.OpenFile(path)
+	assert.Equal(t, []domain.TenantID{"acme"}, reopened.Tenants())
+}
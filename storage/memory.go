@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+type key struct {
+	tenant domain.TenantID
+	id     domain.ShipmentID
+}
+
+// Memory keeps shipments of every tenant in one place. It is only reachable
+// through ForTenant, so lookups can never cross tenant boundaries.
+type Memory struct {
+	mu        sync.Mutex
+	shipments map[key]domain.Shipment
+	sequences map[domain.TenantID]domain.ShipmentID
+	persist   func(map[key]domain.Shipment) error
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		shipments: map[key]domain.Shipment{},
+		sequences: map[domain.TenantID]domain.ShipmentID{},
+	}
+}
+
+func (m *Memory) ForTenant(tenant domain.TenantID) TenantStore {
+	return TenantStore{m, tenant}
+}
+
+// Tenants lists every tenant with at least one stored shipment, for jobs that
+// have to visit all of them.
+func (m *Memory) Tenants() []domain.TenantID {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := map[domain.TenantID]bool{}
+	var tenants []domain.TenantID
+	for k := range m.shipments {
+		if !seen[k.tenant] {
+			seen[k.tenant] = true
+			tenants = append(tenants, k.tenant)
+		}
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i] < tenants[j]
+	})
+
+	return tenants
+}
+
+// Flush writes the current shipments through to the backing file, if any.
+func (m *Memory) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.persist == nil {
+		return nil
+	}
+
+	return m.persist(m.shipments)
+}
+
+type TenantStore struct {
+	memory *Memory
+	tenant domain.TenantID
+}
+
+func (t TenantStore) GetByID(id domain.ShipmentID) (domain.Shipment, error) {
+	if t.tenant == "" {
+		return domain.Shipment{}, MissingTenant
+	}
+
+	t.memory.mu.Lock()
+	defer t.memory.mu.Unlock()
+
+	return t.memory.shipments[key{t.tenant, id}].Clone(), nil
+}
+
+// Save stamps the shipment with the store's tenant. Shipments already owned
+// by another tenant are rejected instead of being silently reassigned. The
+// store keeps its own copy, so later changes to s are not stored until s is
+// saved again.
+func (t TenantStore) Save(s *domain.Shipment) error {
+	if t.tenant == "" {
+		return MissingTenant
+	}
+	if s.Tenant != "" && s.Tenant != t.tenant {
+		return CrossTenantAccess
+	}
+
+	t.memory.mu.Lock()
+	defer t.memory.mu.Unlock()
+
+	k := key{t.tenant, s.ID}
+	stored, found := t.memory.shipments[k]
+	if err := checkVersion(*s, stored, found); err != nil {
+		return err
+	}
+
+	saved := s.Clone()
+	saved.Tenant = t.tenant
+	saved.Version++
+	t.memory.shipments[k] = saved.Clone()
+	if t.memory.persist != nil {
+		if err := t.memory.persist(t.memory.shipments); err != nil {
+			if found {
+				t.memory.shipments[k] = stored
+			} else {
+				delete(t.memory.shipments, k)
+			}
+			return err
+		}
+	}
+	*s = saved
+
+	return nil
+}
+
+func (t TenantStore) List() ([]domain.Shipment, error) {
+	if t.tenant == "" {
+		return nil, MissingTenant
+	}
+
+	t.memory.mu.Lock()
+	defer t.memory.mu.Unlock()
+
+	var shipments []domain.Shipment
+	for k, s := range t.memory.shipments {
+		if k.tenant == t.tenant {
+			shipments = append(shipments, s.Clone())
+		}
+	}
+	sort.Slice(shipments, func(i, j int) bool {
+		return shipments[i].ID < shipments[j].ID
+	})
+
+	return shipments, nil
+}
+
+func (t TenantStore) Next() domain.ShipmentID {
+	t.memory.mu.Lock()
+	defer t.memory.mu.Unlock()
+
+	t.memory.sequences[t.tenant]++
+
+	return t.memory.sequences[t.tenant]
+}
@@ -0,0 +1,114 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/facucachomeli/workshop-go-testing/storage"
+	"github.com/facucachomeli/workshop-go-testing/storage/storagetest"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantStore_GetByID_OtherTenant(t *testing.T) {
+	m := storage.NewMemory()
+	acme := m.ForTenant("acme")
+	globex := m.ForTenant("globex")
+
+	s := shipmenttest.AShipment().Build()
+	err := acme.Save(&s)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.TenantID("acme"), s.Tenant)
+
+	found, err := globex.GetByID(1)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.True(t, found.IsNil(), "expected shipment to be nil but got %#v", found)
+
+	found, err = acme.GetByID(1)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, s, found)
+}
+
+func TestTenantStore_Save_CrossTenantAccess(t *testing.T) {
+	m := storage.NewMemory()
+	s := domain.Shipment{ID: 1, Tenant: "acme", State: domain.Created, Origin: "valid origin", Destination: "valid destination"}
+
+	err := m.ForTenant("globex").Save(&s)
+
+	assert.Equal(t, storage.CrossTenantAccess, err)
+	assert.Equal(t, domain.TenantID("acme"), s.Tenant)
+	list, _ := m.ForTenant("globex").List()
+	assert.Empty(t, list)
+}
+
+func TestTenantStore_MissingTenant(t *testing.T) {
+	store := storage.NewMemory().ForTenant("")
+
+	_, err := store.GetByID(1)
+	assert.Equal(t, storage.MissingTenant, err)
+	assert.Equal(t, storage.MissingTenant, store.Save(&domain.Shipment{ID: 1}))
+	_, err = store.List()
+	assert.Equal(t, storage.MissingTenant, err)
+}
+
+func TestTenantStore_List(t *testing.T) {
+	m := storage.NewMemory()
+	acme := m.ForTenant("acme")
+	for _, id := range []domain.ShipmentID{2, 1} {
+		acme.Save(&domain.Shipment{ID: id, State: domain.Created})
+	}
+	m.ForTenant("globex").Save(&domain.Shipment{ID: 3, State: domain.Created})
+
+	list, err := acme.List()
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, domain.ShipmentID(1), list[0].ID)
+		assert.Equal(t, domain.ShipmentID(2), list[1].ID)
+	}
+}
+
+func TestTenantStore_Next_PerTenant(t *testing.T) {
+	m := storage.NewMemory()
+	acme := m.ForTenant("acme")
+	globex := m.ForTenant("globex")
+
+	assert.Equal(t, domain.ShipmentID(1), acme.Next())
+	assert.Equal(t, domain.ShipmentID(2), acme.Next())
+	assert.Equal(t, domain.ShipmentID(1), globex.Next())
+	assert.Equal(t, domain.ShipmentID(3), acme.Next())
+}
+
+func TestTenantStore_ShipmentUseCase(t *testing.T) {
+	m := storage.NewMemory()
+	acme := m.ForTenant("acme")
+	globex := m.ForTenant("globex")
+	admin := usecase.Actor{ID: "admin-1", Role: usecase.Admin}
+
+	created, err := usecase.NewShipmentUseCase(acme.Save, acme, acme.Next).Create(admin, "valid origin", "valid destination")
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, domain.TenantID("acme"), created.Tenant)
+
+	_, err = usecase.NewShipmentUseCase(globex.Save, globex, globex.Next).Handle(admin, created.ID)
+	assert.Equal(t, usecase.ShipmentDoesNotExist, err)
+
+	s, _ := acme.GetByID(created.ID)
+	assert.Equal(t, domain.Created, s.State)
+}
+
+func TestMemory_Contract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Repository {
+		return storage.NewMemory().ForTenant("acme")
+	})
+}
+
+func TestMemory_Tenants(t *testing.T) {
+	m := storage.NewMemory()
+	m.ForTenant("globex").Save(&domain.Shipment{ID: 1, State: domain.Created})
+	m.ForTenant("acme").Save(&domain.Shipment{ID: 1, State: domain.Created})
+	m.ForTenant("acme").Save(&domain.Shipment{ID: 2, State: domain.Created})
+
+	assert.Equal(t, []domain.TenantID{"acme", "globex"}, m.Tenants())
+	assert.Nil(t, m.Flush())
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+var MissingTenant = errors.New("Missing tenant")
+var CrossTenantAccess = errors.New("Shipment belongs to another tenant")
+var DuplicateShipment = errors.New("Shipment already exists")
+var VersionConflict = errors.New("Shipment was modified concurrently")
+
+type Repository interface {
+	GetByID(domain.ShipmentID) (domain.Shipment, error)
+	Save(*domain.Shipment) error
+	List() ([]domain.Shipment, error)
+}
+
+// checkVersion implements optimistic locking: a shipment with Version 0 is
+// an insert, anything else must match the stored version to be updated.
+func checkVersion(s domain.Shipment, stored domain.Shipment, found bool) error {
+	if s.Version == 0 {
+		if found {
+			return DuplicateShipment
+		}
+		return nil
+	}
+	if !found || stored.Version != s.Version {
+		return VersionConflict
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+// Package storagetest holds the contract every storage.Repository must
+// honour. Implementations run it from their own tests:
+//
+//	storagetest.Run(t, func(t *testing.T) storage.Repository {
+//		return storage.NewMemory().ForTenant("acme")
+//	})
+package storagetest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// Run executes the whole suite. factory must return an empty repository on
+// every call.
+func Run(t *testing.T, factory func(t *testing.T) storage.Repository) {
+	t.Run("GetMissing", func(t *testing.T) { testGetMissing(t, factory(t)) })
+	t.Run("Insert", func(t *testing.T) { testInsert(t, factory(t)) })
+	t.Run("DuplicateInsert", func(t *testing.T) { testDuplicateInsert(t, factory(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, factory(t)) })
+	t.Run("VersionConflict", func(t *testing.T) { testVersionConflict(t, factory(t)) })
+	t.Run("UpdateMissing", func(t *testing.T) { testUpdateMissing(t, factory(t)) })
+	t.Run("List", func(t *testing.T) { testList(t, factory(t)) })
+	t.Run("Isolation", func(t *testing.T) { testIsolation(t, factory(t)) })
+	t.Run("ConcurrentInserts", func(t *testing.T) { testConcurrentInserts(t, factory(t)) })
+	t.Run("ConcurrentUpdates", func(t *testing.T) { testConcurrentUpdates(t, factory(t)) })
+}
+
+func shipment(id domain.ShipmentID) domain.Shipment {
+	return domain.Shipment{
+		ID:          id,
+		State:       domain.Created,
+		Origin:      "valid origin",
+		Destination: "valid destination",
+	}
+}
+
+func insert(t *testing.T, r storage.Repository, id domain.ShipmentID) domain.Shipment {
+	s := shipment(id)
+	if err := r.Save(&s); err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+
+	return s
+}
+
+func testGetMissing(t *testing.T, r storage.Repository) {
+	s, err := r.GetByID(1)
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.True(t, s.IsNil(), "expected shipment to be nil but got %#v", s)
+}
+
+func testInsert(t *testing.T, r storage.Repository) {
+	s := shipment(1)
+
+	err := r.Save(&s)
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, 1, s.Version)
+	found, err := r.GetByID(1)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, s, found)
+}
+
+func testDuplicateInsert(t *testing.T, r storage.Repository) {
+	original := insert(t, r, 1)
+	duplicate := shipment(1)
+	duplicate.Origin = "another origin"
+
+	err := r.Save(&duplicate)
+
+	assert.Equal(t, storage.DuplicateShipment, err)
+	assert.Equal(t, 0, duplicate.Version)
+	found, _ := r.GetByID(1)
+	assert.Equal(t, original, found)
+}
+
+func testUpdate(t *testing.T, r storage.Repository) {
+	insert(t, r, 1)
+	s, _ := r.GetByID(1)
+	s.State = domain.Handled
+
+	err := r.Save(&s)
+
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, 2, s.Version)
+	found, _ := r.GetByID(1)
+	assert.Equal(t, s, found)
+}
+
+func testVersionConflict(t *testing.T, r storage.Repository) {
+	insert(t, r, 1)
+	first, _ := r.GetByID(1)
+	second, _ := r.GetByID(1)
+	first.State = domain.Handled
+	second.State = domain.Cancelled
+
+	assert.Nil(t, r.Save(&first))
+	err := r.Save(&second)
+
+	assert.Equal(t, storage.VersionConflict, err)
+	assert.Equal(t, 1, second.Version)
+	found, _ := r.GetByID(1)
+	assert.Equal(t, domain.Handled, found.State)
+}
+
+func testUpdateMissing(t *testing.T, r storage.Repository) {
+	s := shipment(1)
+	s.Version = 3
+
+	err := r.Save(&s)
+
+	assert.Equal(t, storage.VersionConflict, err)
+	found, _ := r.GetByID(1)
+	assert.True(t, found.IsNil(), "expected shipment to be nil but got %#v", found)
+}
+
+func testList(t *testing.T, r storage.Repository) {
+	list, err := r.List()
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Empty(t, list)
+
+	insert(t, r, 2)
+	insert(t, r, 1)
+
+	list, err = r.List()
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, domain.ShipmentID(1), list[0].ID)
+		assert.Equal(t, domain.ShipmentID(2), list[1].ID)
+	}
+}
+
+func nested(id domain.ShipmentID) domain.Shipment {
+	s := shipment(id)
+	s.Legs = []domain.Leg{{From: "valid origin", To: "valid destination"}}
+	s.Parcels = []domain.Parcel{{Weight: 1000}}
+	s.Proof = &domain.ProofOfDelivery{RecipientName: "Jane Doe", Signature: []byte("signature")}
+
+	return s
+}
+
+func assertNested(t *testing.T, r storage.Repository, msg string) {
+	expected := nested(1)
+	s, err := r.GetByID(1)
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	assert.Equal(t, expected.Legs, s.Legs, msg)
+	assert.Equal(t, expected.Parcels, s.Parcels, msg)
+	assert.Equal(t, expected.Proof, s.Proof, msg)
+}
+
+func testIsolation(t *testing.T, r storage.Repository) {
+	s := nested(1)
+	if err := r.Save(&s); err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+
+	s.Legs[0].To = "changed"
+	s.Parcels[0].Weight = 1
+	s.Proof.Signature[0] = 'x'
+	assertNested(t, r, "expected stored shipment not to change with the saved copy")
+
+	read, _ := r.GetByID(1)
+	read.Legs[0].To = "changed"
+	read.Parcels[0].Weight = 1
+	read.Proof.Signature[0] = 'x'
+	assertNested(t, r, "expected stored shipment not to change with a read copy")
+
+	list, _ := r.List()
+	if assert.Len(t, list, 1) {
+		list[0].Legs[0].To = "changed"
+		list[0].Proof.Signature[0] = 'x'
+	}
+	assertNested(t, r, "expected stored shipment not to change with a listed copy")
+}
+
+func testConcurrentInserts(t *testing.T, r storage.Repository) {
+	const n = 20
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := shipment(domain.ShipmentID(i + 1))
+			errs[i] = r.Save(&s)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		assert.Nilf(t, err, "expected insert %d to succeed but got '%s'", i+1, err)
+	}
+	list, _ := r.List()
+	assert.Len(t, list, n)
+}
+
+func testConcurrentUpdates(t *testing.T, r storage.Repository) {
+	const n = 20
+	insert(t, r, 1)
+
+	// Every writer reads the same version before any of them saves, otherwise
+	// a late reader would see the winner's write and update it legitimately.
+	copies := make([]domain.Shipment, n)
+	for i := range copies {
+		copies[i], _ = r.GetByID(1)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			copies[i].State = domain.Handled
+			errs[i] = r.Save(&copies[i])
+		}(i)
+	}
+	wg.Wait()
+
+	saved := 0
+	for _, err := range errs {
+		if err == nil {
+			saved++
+		} else {
+			assert.Equal(t, storage.VersionConflict, err)
+		}
+	}
+	assert.Equal(t, 1, saved, "expected exactly one concurrent update to win")
+	found, _ := r.GetByID(1)
+	assert.Equal(t, 2, found.Version)
+}
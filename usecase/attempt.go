@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+var DeliveryAttemptCanNotBeReported = errors.New("Delivery attempt can not be reported")
+
+func (uc shipmentUseCase) ReportDeliveryAttempt(actor Actor, id domain.ShipmentID, reason domain.FailureReason) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionReportAttempt) {
+		return domain.Shipment{}, Forbidden
+	}
+
+	s, err := uc.getter.GetByID(id)
+	if err != nil {
+		return domain.Shipment{}, CouldNotCheckExistingShipment
+	}
+
+	if s.IsNil() {
+		return domain.Shipment{}, ShipmentDoesNotExist
+	}
+
+	if s.IsOnHold() {
+		return s, ShipmentOnHold
+	}
+
+	if err := s.FailDelivery(reason, uc.now(), uc.maxDeliveryAttempts); err != nil {
+		return s, DeliveryAttemptCanNotBeReported
+	}
+
+	if err := uc.save(&s); err != nil {
+		return domain.Shipment{}, CouldNotSaveShipment
+	}
+
+	uc.publish(s)
+
+	return s, nil
+}
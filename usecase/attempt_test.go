@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
+)
+
+func TestShipmentUseCase_ReportDeliveryAttempt_ShipmentDoesNotExist(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, nil)
+	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+	s, err := uc.ReportDeliveryAttempt(admin, domain.ShipmentID(1), domain.RecipientAbsent)
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.ShipmentDoesNotExist {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentDoesNotExist, err)
+	}
+	if !s.IsNil() {
+		t.Errorf("expected shipment to be nil but got %#v", s)
+	}
+}
+
+func TestShipmentUseCase_ReportDeliveryAttempt_DeliveryAttemptCanNotBeReported(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().Build(), nil)
+	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+	s, err := uc.ReportDeliveryAttempt(admin, domain.ShipmentID(1), domain.RecipientAbsent)
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.DeliveryAttemptCanNotBeReported {
+		t.Errorf("expected '%s' error but got '%s'", usecase.DeliveryAttemptCanNotBeReported, err)
+	}
+	if s.State != domain.Created {
+		t.Errorf("expected shipment to be Created but got %s", s.State)
+	}
+}
+
+func TestShipmentUseCase_ReportDeliveryAttempt_OK(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	stored := shipmenttest.AShipment().InState(domain.Shipped).Build()
+	getter := usecasetest.NewGetter(t).ReturnsFunc(func(domain.ShipmentID) (domain.Shipment, error) {
+		return stored, nil
+	})
+	save := func(s *domain.Shipment) error {
+		stored = *s
+		return nil
+	}
+	uc := usecase.NewShipmentUseCase(save, getter, nil).
+		WithClock(func() time.Time { return at }).
+		WithMaxDeliveryAttempts(2)
+
+	s, err := uc.ReportDeliveryAttempt(admin, domain.ShipmentID(1), domain.RecipientAbsent)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.State != domain.Shipped {
+		t.Errorf("expected shipment to be Shipped but got %s", s.State)
+	}
+
+	s, err = uc.ReportDeliveryAttempt(admin, domain.ShipmentID(1), domain.RecipientAbsent)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.State != domain.DeliveryFailed {
+		t.Errorf("expected shipment to be DeliveryFailed but got %s", s.State)
+	}
+	if len(stored.Attempts) != 2 {
+		t.Fatalf("expected 2 attempts to be saved but got %d", len(stored.Attempts))
+	}
+	if !stored.Attempts[1].At.Equal(at) {
+		t.Errorf("expected attempt at '%v' but got '%v'", at, stored.Attempts[1].At)
+	}
+}
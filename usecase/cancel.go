@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+var ShipmentCanNotBeCancelled = errors.New("Shipment can not be cancelled")
+var CouldNotRequestRefund = errors.New("Could not request refund")
+
+type RefundInstruction = domain.RefundInstruction
+
+func (uc shipmentUseCase) Cancel(actor Actor, id domain.ShipmentID, reason domain.CancellationReason) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionCancel) {
+		return domain.Shipment{}, Forbidden
+	}
+
+	s, err := uc.getter.GetByID(id)
+	if err != nil {
+		return domain.Shipment{}, CouldNotCheckExistingShipment
+	}
+
+	if s.IsNil() {
+		return domain.Shipment{}, ShipmentDoesNotExist
+	}
+
+	if s.IsOnHold() {
+		return s, ShipmentOnHold
+	}
+
+	err = s.Cancel(reason, uc.fees, uc.now())
+	if err == domain.ShipmentAlreadyCancelled && s.HasPendingRefund() {
+		return uc.requestRefund(s)
+	}
+	if err != nil {
+		return s, ShipmentCanNotBeCancelled
+	}
+
+	if err := uc.save(&s); err != nil {
+		return domain.Shipment{}, CouldNotSaveShipment
+	}
+
+	uc.publish(s)
+
+	return uc.requestRefund(s)
+}
+
+// requestRefund hands the refund off and records that it was. When either
+// step fails the refund stays pending and cancelling again retries it, so
+// the same instruction can be sent more than once.
+func (uc shipmentUseCase) requestRefund(s domain.Shipment) (domain.Shipment, error) {
+	err := uc.refund(RefundInstruction{
+		ShipmentID: s.ID,
+		Reason:     s.Cancellation.Reason,
+		Fee:        s.Cancellation.Fee,
+		Currency:   s.Cancellation.Currency,
+	})
+	if err != nil {
+		return s, CouldNotRequestRefund
+	}
+
+	s.MarkRefundRequested()
+	if err := uc.save(&s); err != nil {
+		return s, CouldNotSaveShipment
+	}
+
+	return s, nil
+}
@@ -0,0 +1,125 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
+)
+
+func TestShipmentUseCase_Cancel_ShipmentCanNotBeCancelled(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().InState(domain.Shipped).Build(), nil)
+	refunds := usecasetest.NewRefunds(t)
+	uc := usecase.NewShipmentUseCase(nil, getter, nil).WithRefunds(refunds.Refund)
+
+	s, err := uc.Cancel(admin, domain.ShipmentID(1), domain.CustomerRequest)
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.ShipmentCanNotBeCancelled {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentCanNotBeCancelled, err)
+	}
+	if s.State != domain.Shipped {
+		t.Errorf("expected shipment to be Shipped but got %s", s.State)
+	}
+}
+
+func TestShipmentUseCase_Cancel_CouldNotRequestRefund(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().Build(), nil)
+	saver := usecasetest.NewSaver(t).ExpectSaved(1, 1)
+	refunds := usecasetest.NewRefunds(t).Fails(errors.New("Billing error")).ExpectCalled(1)
+	uc := usecase.NewShipmentUseCase(saver.Save, getter, nil).WithRefunds(refunds.Refund)
+
+	s, err := uc.Cancel(admin, domain.ShipmentID(1), domain.CustomerRequest)
+	if err != usecase.CouldNotRequestRefund {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotRequestRefund, err)
+	}
+	if s.State != domain.Cancelled {
+		t.Errorf("expected shipment to be Cancelled but got %s", s.State)
+	}
+}
+
+func TestShipmentUseCase_Cancel_OK(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().InState(domain.Handled).Build(), nil)
+	saver := usecasetest.NewSaver(t).ExpectSaved(1, 2)
+	refunds := usecasetest.NewRefunds(t).ExpectCalled(1)
+	fees := domain.FeeSchedule{
+		Currency: "ARS",
+		Fees:     map[domain.ShipmentState]int64{domain.Handled: 15000},
+	}
+	uc := usecase.NewShipmentUseCase(saver.Save, getter, nil).
+		WithFeeSchedule(fees).
+		WithRefunds(refunds.Refund)
+
+	s, err := uc.Cancel(admin, domain.ShipmentID(1), domain.AddressIssue)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	saved := saver.Last()
+	if s.State != domain.Cancelled || saved.State != domain.Cancelled {
+		t.Errorf("expected shipment to be saved as Cancelled but got %s", saved.State)
+	}
+	expected := usecase.RefundInstruction{
+		ShipmentID: domain.ShipmentID(1),
+		Reason:     domain.AddressIssue,
+		Fee:        15000,
+		Currency:   "ARS",
+	}
+	if instructions := refunds.Requested(); instructions[0] != expected {
+		t.Errorf("expected refund instruction %#v but got %#v", expected, instructions)
+	}
+	if saved.HasPendingRefund() {
+		t.Errorf("expected the refund to be saved as requested")
+	}
+}
+
+func TestShipmentUseCase_Cancel_RetriesPendingRefund(t *testing.T) {
+	stored := shipmenttest.AShipment().InState(domain.Handled).Build()
+	getter := usecasetest.NewGetter(t).ReturnsFunc(func(domain.ShipmentID) (domain.Shipment, error) {
+		return stored, nil
+	})
+	save := func(s *domain.Shipment) error {
+		stored = *s
+		return nil
+	}
+	var instructions []usecase.RefundInstruction
+	failing := true
+	refund := func(r usecase.RefundInstruction) error {
+		if failing {
+			return errors.New("Billing error")
+		}
+		instructions = append(instructions, r)
+		return nil
+	}
+	uc := usecase.NewShipmentUseCase(save, getter, nil).WithRefunds(refund)
+
+	_, err := uc.Cancel(admin, domain.ShipmentID(1), domain.AddressIssue)
+	if err != usecase.CouldNotRequestRefund {
+		t.Fatalf("expected '%s' error but got '%s'", usecase.CouldNotRequestRefund, err)
+	}
+	if !stored.HasPendingRefund() {
+		t.Fatalf("expected the refund to be saved as pending")
+	}
+
+	failing = false
+	s, err := uc.Cancel(admin, domain.ShipmentID(1), domain.CustomerRequest)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.State != domain.Cancelled || stored.HasPendingRefund() {
+		t.Errorf("expected the refund to be saved as requested")
+	}
+	if len(instructions) != 1 || instructions[0].Reason != domain.AddressIssue {
+		t.Errorf("expected the original refund instruction to be sent but got %#v", instructions)
+	}
+
+	_, err = uc.Cancel(admin, domain.ShipmentID(1), domain.CustomerRequest)
+	if err != usecase.ShipmentCanNotBeCancelled {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentCanNotBeCancelled, err)
+	}
+	if len(instructions) != 1 {
+		t.Errorf("expected the refund not to be sent again but got %#v", instructions)
+	}
+}
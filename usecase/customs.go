@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+var CustomsCanNotBeDeclared = errors.New("Customs can not be declared")
+
+func (uc shipmentUseCase) DeclareCustoms(actor Actor, id domain.ShipmentID, d domain.CustomsDeclaration) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionDeclareCustoms) {
+		return domain.Shipment{}, Forbidden
+	}
+
+	s, err := uc.getter.GetByID(id)
+	if err != nil {
+		return domain.Shipment{}, CouldNotCheckExistingShipment
+	}
+
+	if s.IsNil() {
+		return domain.Shipment{}, ShipmentDoesNotExist
+	}
+
+	if err := s.DeclareCustoms(d); err != nil {
+		return s, CustomsCanNotBeDeclared
+	}
+
+	if err := uc.save(&s); err != nil {
+		return domain.Shipment{}, CouldNotSaveShipment
+	}
+
+	return s, nil
+}
@@ -0,0 +1,123 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/carrier/fake"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
+)
+
+func internationalGetter(t *testing.T, stored *domain.Shipment) *usecasetest.Getter {
+	return usecasetest.NewGetter(t).ReturnsFunc(func(domain.ShipmentID) (domain.Shipment, error) {
+		return *stored, nil
+	})
+}
+
+func declaration() domain.CustomsDeclaration {
+	return domain.CustomsDeclaration{
+		Items:    []domain.CustomsItem{{HSCode: "610910", Description: "Cotton t-shirt", Quantity: 3, UnitValue: 1250}},
+		Currency: "USD",
+		Incoterm: domain.DAP,
+		Exporter: domain.Party{Name: "Exporter SA", Address: "Buenos Aires", Country: "AR"},
+		Importer: domain.Party{Name: "Importer SL", Address: "Madrid", Country: "ES"},
+	}
+}
+
+func TestShipmentUseCase_Create_WithCountries(t *testing.T) {
+	sequence := func() domain.ShipmentID {
+		return domain.ShipmentID(1)
+	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, nil)
+	save := func(*domain.Shipment) error {
+		return nil
+	}
+	uc := usecase.NewShipmentUseCase(save, getter, sequence)
+
+	s, err := uc.Create(admin, "Buenos Aires", "Madrid", usecase.WithCountries("AR", "ES"))
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if !s.IsInternational() {
+		t.Errorf("expected shipment to be international but got %#v", s)
+	}
+}
+
+func TestShipmentUseCase_DeclareCustoms_CustomsCanNotBeDeclared(t *testing.T) {
+	stored := domain.Shipment{
+		ID:                 domain.ShipmentID(1),
+		State:              domain.Handled,
+		Origin:             "Buenos Aires",
+		Destination:        "Madrid",
+		OriginCountry:      "AR",
+		DestinationCountry: "UY",
+	}
+	uc := usecase.NewShipmentUseCase(nil, internationalGetter(t, &stored), nil)
+
+	s, err := uc.DeclareCustoms(admin, domain.ShipmentID(1), declaration())
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.CustomsCanNotBeDeclared {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CustomsCanNotBeDeclared, err)
+	}
+	if s.Customs != nil {
+		t.Errorf("expected no customs declaration but got %#v", s.Customs)
+	}
+}
+
+func TestShipmentUseCase_Ship_IncompleteCustomsDeclaration(t *testing.T) {
+	stored := domain.Shipment{
+		ID:                 domain.ShipmentID(1),
+		State:              domain.Handled,
+		Origin:             "Buenos Aires",
+		Destination:        "Madrid",
+		OriginCountry:      "AR",
+		DestinationCountry: "ES",
+	}
+	c := fake.New("FK")
+	uc := usecase.NewShipmentUseCase(nil, internationalGetter(t, &stored), nil).
+		WithCarriers(fakeCarriers(c))
+
+	s, err := uc.Ship(admin, domain.ShipmentID(1))
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.IncompleteCustomsDeclaration {
+		t.Errorf("expected '%s' error but got '%s'", usecase.IncompleteCustomsDeclaration, err)
+	}
+	if s.State != domain.Handled {
+		t.Errorf("expected shipment to be Handled but got %s", s.State)
+	}
+	if _, ok := c.Booked("FK00000001"); ok {
+		t.Errorf("expected no pickup to be booked")
+	}
+}
+
+func TestShipmentUseCase_DeclareCustoms_OK(t *testing.T) {
+	stored := domain.Shipment{
+		ID:                 domain.ShipmentID(1),
+		State:              domain.Handled,
+		Origin:             "Buenos Aires",
+		Destination:        "Madrid",
+		OriginCountry:      "AR",
+		DestinationCountry: "ES",
+	}
+	save := func(s *domain.Shipment) error {
+		stored = *s
+		return nil
+	}
+	uc := usecase.NewShipmentUseCase(save, internationalGetter(t, &stored), nil).
+		WithCarriers(fakeCarriers(fake.New("FK")))
+
+	if _, err := uc.DeclareCustoms(admin, domain.ShipmentID(1), declaration()); err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+
+	s, err := uc.Ship(admin, domain.ShipmentID(1))
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.State != domain.Shipped {
+		t.Errorf("expected shipment to be Shipped but got %s", s.State)
+	}
+}
@@ -0,0 +1,94 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/carrier/fake"
+	"github.com/facucachomeli/workshop-go-testing/dangerousgoods"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
+)
+
+var fireworks = domain.DangerousGood{UNNumber: "UN0336", Class: 1}
+var lithiumIon = domain.DangerousGood{UNNumber: "UN3480", Class: 9}
+
+func TestShipmentUseCase_Create_DangerousGoodsViolation(t *testing.T) {
+	sequence := func() domain.ShipmentID {
+		return domain.ShipmentID(1)
+	}
+	uc := usecase.NewShipmentUseCase(nil, nil, sequence)
+
+	s, err := uc.Create(admin, "valid origin", "valid destination", usecase.WithParcels(domain.Parcel{
+		Weight:         500,
+		DangerousGoods: []domain.DangerousGood{fireworks},
+	}))
+	violations, ok := err.(dangerousgoods.Violations)
+	if !ok {
+		t.Fatalf("expected violations but got %#v", err)
+	}
+	if len(violations) != 1 || violations[0].Rule != "explosives" {
+		t.Errorf("expected only the explosives rule to be violated but got %s", err)
+	}
+	if !s.IsNil() {
+		t.Errorf("expected shipment to be nil but got %#v", s)
+	}
+}
+
+func TestShipmentUseCase_Create_InvalidParcel(t *testing.T) {
+	sequence := func() domain.ShipmentID {
+		return domain.ShipmentID(1)
+	}
+	uc := usecase.NewShipmentUseCase(nil, nil, sequence)
+
+	_, err := uc.Create(admin, "valid origin", "valid destination", usecase.WithParcels(domain.Parcel{Weight: 0}))
+	if err != usecase.CouldNotCreateShipment {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotCreateShipment, err)
+	}
+}
+
+func TestShipmentUseCase_Ship_DangerousGoodsViolation(t *testing.T) {
+	stored := domain.Shipment{
+		ID:          domain.ShipmentID(1),
+		State:       domain.Handled,
+		Origin:      "valid origin",
+		Destination: "valid destination",
+		Parcels:     []domain.Parcel{{Weight: 500, DangerousGoods: []domain.DangerousGood{lithiumIon}}},
+		Legs:        []domain.Leg{{From: "valid origin", To: "valid destination", Carrier: "carrier", Mode: domain.Air}},
+	}
+	getter := usecasetest.NewGetter(t).ReturnsFunc(func(domain.ShipmentID) (domain.Shipment, error) {
+		return stored, nil
+	})
+	c := fake.New("FK")
+	uc := usecase.NewShipmentUseCase(nil, getter, nil).
+		WithCarriers(fakeCarriers(c))
+
+	s, err := uc.Ship(admin, domain.ShipmentID(1))
+	if _, ok := err.(dangerousgoods.Violations); !ok {
+		t.Fatalf("expected violations but got %#v", err)
+	}
+	if s.State != domain.Handled {
+		t.Errorf("expected shipment to be Handled but got %s", s.State)
+	}
+	if _, ok := c.Booked("FK00000001"); ok {
+		t.Errorf("expected no pickup to be booked")
+	}
+}
+
+func TestShipmentUseCase_Ship_ChecksDangerousGoodsBeforePickingCarrier(t *testing.T) {
+	violation := dangerousgoods.Violations{{Rule: "explosives"}}
+	hazmat := usecasetest.NewDangerousGoods(t).Returns(violation)
+	carriers := usecasetest.NewCarriers(t)
+	saver := usecasetest.NewSaver(t)
+	uc := usecase.NewShipmentUseCase(saver.Save, handledGetter(t), nil).
+		WithDangerousGoods(hazmat).
+		WithCarriers(carriers)
+
+	_, err := uc.Ship(admin, domain.ShipmentID(1))
+	if _, ok := err.(dangerousgoods.Violations); !ok {
+		t.Fatalf("expected violations but got %#v", err)
+	}
+	if checked := hazmat.Checked(); len(checked) != 1 || checked[0].ID != 1 {
+		t.Errorf("expected shipment 1 to be checked but got %#v", checked)
+	}
+}
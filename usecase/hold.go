@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+type holdUseCase struct {
+	save   func(*domain.Shipment) error
+	getter Getter
+	lister Lister
+	now    func() time.Time
+	policy Policy
+}
+
+func NewHoldUseCase(save func(*domain.Shipment) error, getter Getter, lister Lister, now func() time.Time) holdUseCase {
+	return holdUseCase{save, getter, lister, now, DefaultPolicy()}
+}
+
+func (uc holdUseCase) WithPolicy(p Policy) holdUseCase {
+	uc.policy = p
+	return uc
+}
+
+var ShipmentCanNotBeHeld = errors.New("Shipment can not be held")
+var ShipmentCanNotBeReleased = errors.New("Shipment can not be released")
+
+// PlaceHold records the actor as the one who placed the hold.
+func (uc holdUseCase) PlaceHold(actor Actor, id domain.ShipmentID, reason string) (domain.Shipment, error) {
+	return uc.update(actor, id, ShipmentCanNotBeHeld, func(s *domain.Shipment) error {
+		return s.PlaceHold(reason, actor.ID, uc.now())
+	})
+}
+
+func (uc holdUseCase) Release(actor Actor, id domain.ShipmentID) (domain.Shipment, error) {
+	return uc.update(actor, id, ShipmentCanNotBeReleased, func(s *domain.Shipment) error {
+		return s.Release()
+	})
+}
+
+func (uc holdUseCase) OnHold(actor Actor) ([]domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionView) {
+		return nil, Forbidden
+	}
+
+	shipments, err := uc.lister.List()
+	if err != nil {
+		return nil, CouldNotListShipments
+	}
+
+	var held []domain.Shipment
+	for _, s := range shipments {
+		if s.IsOnHold() {
+			held = append(held, s)
+		}
+	}
+
+	return held, nil
+}
+
+func (uc holdUseCase) update(actor Actor, id domain.ShipmentID, failure error, update func(*domain.Shipment) error) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionHold) {
+		return domain.Shipment{}, Forbidden
+	}
+
+	s, err := uc.getter.GetByID(id)
+	if err != nil {
+		return domain.Shipment{}, CouldNotCheckExistingShipment
+	}
+
+	if s.IsNil() {
+		return domain.Shipment{}, ShipmentDoesNotExist
+	}
+
+	if err := update(&s); err != nil {
+		return s, failure
+	}
+
+	if err := uc.save(&s); err != nil {
+		return domain.Shipment{}, CouldNotSaveShipment
+	}
+
+	return s, nil
+}
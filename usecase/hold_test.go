@@ -0,0 +1,120 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
+)
+
+func TestHoldUseCase_PlaceHold_ShipmentDoesNotExist(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, nil)
+	uc := usecase.NewHoldUseCase(nil, getter, nil, time.Now)
+
+	s, err := uc.PlaceHold(admin, domain.ShipmentID(1), "sanctions check")
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.ShipmentDoesNotExist {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentDoesNotExist, err)
+	}
+	if !s.IsNil() {
+		t.Errorf("expected shipment to be nil but got %#v", s)
+	}
+}
+
+func TestHoldUseCase_Forbidden(t *testing.T) {
+	driver := usecase.Actor{ID: "driver-1", Role: usecase.Driver}
+	uc := usecase.NewHoldUseCase(nil, usecasetest.NewGetter(t), nil, time.Now)
+
+	if _, err := uc.PlaceHold(driver, domain.ShipmentID(1), "sanctions check"); err != usecase.Forbidden {
+		t.Errorf("expected '%s' error but got '%v'", usecase.Forbidden, err)
+	}
+	if _, err := uc.Release(driver, domain.ShipmentID(1)); err != usecase.Forbidden {
+		t.Errorf("expected '%s' error but got '%v'", usecase.Forbidden, err)
+	}
+}
+
+func TestHoldUseCase_Release_ShipmentCanNotBeReleased(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().Build(), nil)
+	uc := usecase.NewHoldUseCase(nil, getter, nil, time.Now)
+
+	_, err := uc.Release(admin, domain.ShipmentID(1))
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.ShipmentCanNotBeReleased {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentCanNotBeReleased, err)
+	}
+}
+
+func TestHoldUseCase_OnHold_Forbidden(t *testing.T) {
+	uc := usecase.NewHoldUseCase(nil, nil, usecasetest.NewLister(t), time.Now)
+
+	held, err := uc.OnHold(usecase.Actor{})
+	if err != usecase.Forbidden {
+		t.Errorf("expected '%s' error but got '%v'", usecase.Forbidden, err)
+	}
+	if held != nil {
+		t.Errorf("expected no shipments but got %#v", held)
+	}
+}
+
+func TestHoldUseCase_OnHold_CouldNotListShipments(t *testing.T) {
+	lister := usecasetest.NewLister(t).Returns(nil, errors.New("List error"))
+	uc := usecase.NewHoldUseCase(nil, nil, lister, time.Now)
+
+	_, err := uc.OnHold(admin)
+	if err != usecase.CouldNotListShipments {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotListShipments, err)
+	}
+}
+
+func TestHoldUseCase_OK(t *testing.T) {
+	now := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	stored := shipmenttest.AShipment().Build()
+	getter := usecasetest.NewGetter(t).ReturnsFunc(func(domain.ShipmentID) (domain.Shipment, error) {
+		return stored, nil
+	})
+	lister := usecasetest.NewLister(t).ReturnsFunc(func() ([]domain.Shipment, error) {
+		return []domain.Shipment{stored, {ID: 2, State: domain.Created}}, nil
+	})
+	save := func(s *domain.Shipment) error {
+		stored = *s
+		return nil
+	}
+	holds := usecase.NewHoldUseCase(save, getter, lister, func() time.Time { return now })
+	shipments := usecase.NewShipmentUseCase(save, getter, nil)
+
+	s, err := holds.PlaceHold(admin, domain.ShipmentID(1), "sanctions check")
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.Hold == nil || s.Hold.By != admin.ID || !s.Hold.At.Equal(now) {
+		t.Errorf("expected hold by '%s' at '%v' but got %#v", admin.ID, now, s.Hold)
+	}
+
+	held, err := holds.OnHold(admin)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if len(held) != 1 || held[0].ID != 1 {
+		t.Errorf("expected only shipment 1 to be on hold but got %#v", held)
+	}
+
+	if _, err := shipments.Handle(admin, domain.ShipmentID(1)); err != usecase.ShipmentOnHold {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentOnHold, err)
+	}
+
+	if _, err := holds.Release(admin, domain.ShipmentID(1)); err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if _, err := shipments.Handle(admin, domain.ShipmentID(1)); err != nil {
+		t.Errorf("expected error to be nil but got '%s'", err)
+	}
+	if stored.State != domain.Handled {
+		t.Errorf("expected shipment to be Handled but got %s", stored.State)
+	}
+}
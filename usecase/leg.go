@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+var LegCanNotBePlanned = errors.New("Leg can not be planned")
+var LegCanNotDepart = errors.New("Leg can not depart")
+var LegCanNotArrive = errors.New("Leg can not arrive")
+
+func (uc shipmentUseCase) PlanLeg(actor Actor, id domain.ShipmentID, l domain.Leg) (domain.Shipment, error) {
+	return uc.updateLegs(actor, ActionHandle, id, func(s *domain.Shipment) error {
+		if err := s.AddLeg(l); err != nil {
+			return LegCanNotBePlanned
+		}
+		return nil
+	})
+}
+
+// DepartLeg starts a leg of a shipped shipment. Ship books the carrier and
+// runs the customs and dangerous goods checks, so it must come first.
+func (uc shipmentUseCase) DepartLeg(actor Actor, id domain.ShipmentID, leg int) (domain.Shipment, error) {
+	return uc.updateLegs(actor, ActionShip, id, func(s *domain.Shipment) error {
+		if err := s.DepartLeg(leg, uc.now()); err != nil {
+			return LegCanNotDepart
+		}
+		return nil
+	})
+}
+
+func (uc shipmentUseCase) ArriveLeg(actor Actor, id domain.ShipmentID, leg int) (domain.Shipment, error) {
+	return uc.updateLegs(actor, ActionShip, id, func(s *domain.Shipment) error {
+		if err := s.ArriveLeg(leg, uc.now()); err != nil {
+			return LegCanNotArrive
+		}
+		return nil
+	})
+}
+
+func (uc shipmentUseCase) updateLegs(actor Actor, action Action, id domain.ShipmentID, update func(*domain.Shipment) error) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, action) {
+		return domain.Shipment{}, Forbidden
+	}
+
+	s, err := uc.getter.GetByID(id)
+	if err != nil {
+		return domain.Shipment{}, CouldNotCheckExistingShipment
+	}
+
+	if s.IsNil() {
+		return domain.Shipment{}, ShipmentDoesNotExist
+	}
+
+	if s.IsOnHold() {
+		return s, ShipmentOnHold
+	}
+
+	state := s.State
+	if err := update(&s); err != nil {
+		return s, err
+	}
+
+	if err := uc.save(&s); err != nil {
+		return domain.Shipment{}, CouldNotSaveShipment
+	}
+
+	if s.State != state {
+		uc.publish(s)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,169 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/carrier/fake"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
+)
+
+func TestShipmentUseCase_PlanLeg_ShipmentDoesNotExist(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, nil)
+	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+	s, err := uc.PlanLeg(admin, domain.ShipmentID(1), domain.Leg{})
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.ShipmentDoesNotExist {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentDoesNotExist, err)
+	}
+	if !s.IsNil() {
+		t.Errorf("expected shipment to be nil but got %#v", s)
+	}
+}
+
+func TestShipmentUseCase_DepartLeg_LegCanNotDepart(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().InState(domain.Handled).Build(), nil)
+	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+	s, err := uc.DepartLeg(admin, domain.ShipmentID(1), 0)
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.LegCanNotDepart {
+		t.Errorf("expected '%s' error but got '%s'", usecase.LegCanNotDepart, err)
+	}
+	if s.State != domain.Handled {
+		t.Errorf("expected shipment to be Handled but got %s", s.State)
+	}
+}
+
+func TestShipmentUseCase_DepartLeg_Error(t *testing.T) {
+	flammable := domain.Parcel{Weight: 500, DangerousGoods: []domain.DangerousGood{{UNNumber: "UN1203", Class: 3}}}
+	airLeg := domain.Leg{From: "valid origin", To: "valid destination", Carrier: "carrier", Mode: domain.Air}
+	driver := usecase.Actor{ID: "driver-1", Role: usecase.Driver}
+
+	cases := []struct {
+		name          string
+		actor         usecase.Actor
+		shipment      *shipmenttest.Builder
+		expectedError error
+	}{
+		{
+			name:          "Forbidden",
+			actor:         driver,
+			shipment:      shipmenttest.AShipment().InState(domain.Shipped),
+			expectedError: usecase.Forbidden,
+		},
+		{
+			name:          "Not Shipped",
+			actor:         admin,
+			shipment:      shipmenttest.AShipment().InState(domain.Handled),
+			expectedError: usecase.LegCanNotDepart,
+		},
+		{
+			name:          "Unchecked Customs And Dangerous Goods",
+			actor:         admin,
+			shipment:      shipmenttest.AShipment().InState(domain.Handled).Between("AR", "ES").WithParcels(flammable),
+			expectedError: usecase.LegCanNotDepart,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stored := c.shipment.Build()
+			stored.Legs = []domain.Leg{airLeg}
+			getter := usecasetest.NewGetter(t)
+			if c.expectedError != usecase.Forbidden {
+				getter.ReturnsForAny(stored, nil)
+			}
+			uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+			s, err := uc.DepartLeg(c.actor, domain.ShipmentID(1), 0)
+			if err != c.expectedError {
+				t.Errorf("expected '%s' error but got '%s'", c.expectedError, err)
+			}
+			if s.State == domain.Shipped || len(s.Legs) > 0 && s.Legs[0].HasDeparted() {
+				t.Errorf("expected leg not to depart")
+			}
+		})
+	}
+}
+
+func TestShipmentUseCase_Legs_OK(t *testing.T) {
+	now := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	stored := shipmenttest.AShipment().InState(domain.Handled).Build()
+	getter := usecasetest.NewGetter(t).ReturnsFunc(func(domain.ShipmentID) (domain.Shipment, error) {
+		return stored, nil
+	})
+	save := func(s *domain.Shipment) error {
+		stored = *s
+		return nil
+	}
+	var published []domain.ShipmentState
+	publish := func(s domain.Shipment) {
+		published = append(published, s.State)
+	}
+	uc := usecase.NewShipmentUseCase(save, getter, nil).
+		WithPublisher(publish).
+		WithClock(func() time.Time { return now }).
+		WithCarriers(fakeCarriers(fake.New("FK")))
+
+	legs := []domain.Leg{
+		{From: "valid origin", To: "hub", Carrier: "carrier", Mode: domain.Ground},
+		{From: "hub", To: "valid destination", Carrier: "other carrier", Mode: domain.Air},
+	}
+	for _, l := range legs {
+		if _, err := uc.PlanLeg(admin, stored.ID, l); err != nil {
+			t.Fatalf("expected error to be nil but got '%s'", err)
+		}
+	}
+
+	s, err := uc.Ship(admin, stored.ID)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.TrackingNumber != "FK00000001" {
+		t.Errorf("expected pickup to be booked as 'FK00000001' but got '%s'", s.TrackingNumber)
+	}
+
+	s, err = uc.DepartLeg(admin, stored.ID, 0)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if !s.Legs[0].ActualDeparture.Equal(now) {
+		t.Errorf("expected leg to depart at '%v' but got '%v'", now, s.Legs[0].ActualDeparture)
+	}
+
+	s, err = uc.ArriveLeg(admin, stored.ID, 0)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.Location() != "hub" {
+		t.Errorf("expected shipment to be at 'hub' but got '%s'", s.Location())
+	}
+	if !stored.Legs[0].ActualArrival.Equal(now) {
+		t.Errorf("expected leg to arrive at '%v' but got '%v'", now, stored.Legs[0].ActualArrival)
+	}
+	if len(published) != 1 || published[0] != domain.Shipped {
+		t.Errorf("expected only the Shipped state to be published but got %v", published)
+	}
+
+	_, err = uc.Deliver(admin, stored.ID, shipmenttest.ValidProof())
+	if err != usecase.ShipmentCanNotBeDelivered {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentCanNotBeDelivered, err)
+	}
+
+	uc.DepartLeg(admin, stored.ID, 1)
+	uc.ArriveLeg(admin, stored.ID, 1)
+	s, err = uc.Deliver(admin, stored.ID, shipmenttest.ValidProof())
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.State != domain.Delivered {
+		t.Errorf("expected shipment to be Delivered but got %s", s.State)
+	}
+}
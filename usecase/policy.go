@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"errors"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Role string
+
+var Merchant = Role("merchant")
+var Warehouse = Role("warehouse")
+var Driver = Role("driver")
+var Admin = Role("admin")
+
+type Action string
+
+var ActionView = Action("view")
+var ActionCreate = Action("create")
+var ActionHandle = Action("handle")
+var ActionShip = Action("ship")
+var ActionDeliver = Action("deliver")
+var ActionCancel = Action("cancel")
+var ActionReturn = Action("return")
+var ActionReportAttempt = Action("report-attempt")
+var ActionDeclareCustoms = Action("declare-customs")
+var ActionHold = Action("hold")
+
+var Forbidden = errors.New("Forbidden")
+var InvalidPolicy = errors.New("Invalid policy")
+
+type Actor struct {
+	ID   string
+	Role Role
+}
+
+type Policy map[Role][]Action
+
+func DefaultPolicy() Policy {
+	return Policy{
+		Merchant:  {ActionView, ActionCreate, ActionCancel, ActionReturn, ActionDeclareCustoms},
+		Warehouse: {ActionView, ActionHandle, ActionShip, ActionHold},
+		Driver:    {ActionView, ActionDeliver, ActionReportAttempt},
+		Admin: {
+			ActionView, ActionCreate, ActionHandle, ActionShip, ActionDeliver, ActionCancel,
+			ActionReturn, ActionReportAttempt, ActionDeclareCustoms, ActionHold,
+		},
+	}
+}
+
+// ParsePolicy reads a policy from YAML in the form:
+//
+//	roles:
+//	  merchant: [create, cancel]
+//	  driver: [deliver]
+func ParsePolicy(data []byte) (Policy, error) {
+	var doc struct {
+		Roles map[Role][]Action `yaml:"roles"`
+	}
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return nil, InvalidPolicy
+	}
+	if len(doc.Roles) == 0 {
+		return nil, InvalidPolicy
+	}
+
+	for role, actions := range doc.Roles {
+		if role == "" {
+			return nil, InvalidPolicy
+		}
+		for _, a := range actions {
+			if !a.isValid() {
+				return nil, InvalidPolicy
+			}
+		}
+	}
+
+	return Policy(doc.Roles), nil
+}
+
+func (p Policy) Allows(actor Actor, action Action) bool {
+	if actor.ID == "" {
+		return false
+	}
+	for _, a := range p[actor.Role] {
+		if a == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a Action) isValid() bool {
+	switch a {
+	case ActionView, ActionCreate, ActionHandle, ActionShip, ActionDeliver, ActionCancel,
+		ActionReturn, ActionReportAttempt, ActionDeclareCustoms, ActionHold:
+		return true
+	}
+
+	return false
+}
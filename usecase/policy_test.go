@@ -0,0 +1,189 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParsePolicy_InvalidPolicy(t *testing.T) {
+	cases := []struct {
+		name string
+		yaml string
+	}{
+		{
+			name: "Malformed",
+			yaml: "roles: [",
+		},
+		{
+			name: "Empty",
+			yaml: "roles: {}",
+		},
+		{
+			name: "Unknown Field",
+			yaml: "permissions:\n  driver: [deliver]\n",
+		},
+		{
+			name: "Unknown Action",
+			yaml: "roles:\n  driver: [deliver, teleport]\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := usecase.ParsePolicy([]byte(c.yaml))
+			if err != usecase.InvalidPolicy {
+				t.Errorf("expected '%s' error but got '%v'", usecase.InvalidPolicy, err)
+			}
+			if p != nil {
+				t.Errorf("expected policy to be nil but got %#v", p)
+			}
+		})
+	}
+}
+
+func TestParsePolicy_OK(t *testing.T) {
+	p, err := usecase.ParsePolicy([]byte("roles:\n  driver: [deliver]\n  support: [cancel]\n"))
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+
+	support := usecase.Actor{ID: "support-1", Role: usecase.Role("support")}
+	if !p.Allows(support, usecase.ActionCancel) {
+		t.Errorf("expected support to be allowed to cancel")
+	}
+	if p.Allows(support, usecase.ActionDeliver) {
+		t.Errorf("expected support not to be allowed to deliver")
+	}
+}
+
+func TestPolicy_Allows(t *testing.T) {
+	p := usecase.DefaultPolicy()
+	cases := []struct {
+		name     string
+		actor    usecase.Actor
+		action   usecase.Action
+		expected bool
+	}{
+		{name: "Merchant Create", actor: usecase.Actor{ID: "m", Role: usecase.Merchant}, action: usecase.ActionCreate, expected: true},
+		{name: "Merchant Deliver", actor: usecase.Actor{ID: "m", Role: usecase.Merchant}, action: usecase.ActionDeliver, expected: false},
+		{name: "Warehouse Ship", actor: usecase.Actor{ID: "w", Role: usecase.Warehouse}, action: usecase.ActionShip, expected: true},
+		{name: "Driver Deliver", actor: usecase.Actor{ID: "d", Role: usecase.Driver}, action: usecase.ActionDeliver, expected: true},
+		{name: "Driver Cancel", actor: usecase.Actor{ID: "d", Role: usecase.Driver}, action: usecase.ActionCancel, expected: false},
+		{name: "Merchant Return", actor: usecase.Actor{ID: "m", Role: usecase.Merchant}, action: usecase.ActionReturn, expected: true},
+		{name: "Driver Return", actor: usecase.Actor{ID: "d", Role: usecase.Driver}, action: usecase.ActionReturn, expected: false},
+		{name: "Driver View", actor: usecase.Actor{ID: "d", Role: usecase.Driver}, action: usecase.ActionView, expected: true},
+		{name: "Anonymous Admin", actor: usecase.Actor{Role: usecase.Admin}, action: usecase.ActionCreate, expected: false},
+		{name: "Unknown Role", actor: usecase.Actor{ID: "x", Role: usecase.Role("guest")}, action: usecase.ActionCreate, expected: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := p.Allows(c.actor, c.action); actual != c.expected {
+				t.Errorf("expected '%v' but got '%v'", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestShipmentUseCase_Forbidden(t *testing.T) {
+	getter := usecasetest.NewGetter(t)
+	driver := usecase.Actor{ID: "driver-1", Role: usecase.Driver}
+	merchant := usecase.Actor{ID: "merchant-1", Role: usecase.Merchant}
+	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+	cases := []struct {
+		name string
+		call func() (domain.Shipment, error)
+	}{
+		{name: "Create", call: func() (domain.Shipment, error) { return uc.Create(driver, "valid origin", "valid destination") }},
+		{name: "Handle", call: func() (domain.Shipment, error) { return uc.Handle(driver, 1) }},
+		{name: "Ship", call: func() (domain.Shipment, error) { return uc.Ship(merchant, 1) }},
+		{name: "Deliver", call: func() (domain.Shipment, error) { return uc.Deliver(merchant, 1, shipmenttest.ValidProof()) }},
+		{name: "Cancel", call: func() (domain.Shipment, error) { return uc.Cancel(driver, 1, domain.CustomerRequest) }},
+		{name: "ReportDeliveryAttempt", call: func() (domain.Shipment, error) { return uc.ReportDeliveryAttempt(merchant, 1, domain.RecipientAbsent) }},
+		{name: "DeclareCustoms", call: func() (domain.Shipment, error) { return uc.DeclareCustoms(driver, 1, domain.CustomsDeclaration{}) }},
+		{name: "PlanLeg", call: func() (domain.Shipment, error) { return uc.PlanLeg(driver, 1, domain.Leg{}) }},
+		{name: "ArriveLeg", call: func() (domain.Shipment, error) { return uc.ArriveLeg(merchant, 1, 0) }},
+		{name: "InitiateReturn", call: func() (domain.Shipment, error) { return uc.InitiateReturn(driver, 1) }},
+		{name: "Anonymous InitiateReturn", call: func() (domain.Shipment, error) { return uc.InitiateReturn(usecase.Actor{}, 1) }},
+		{name: "Anonymous Get", call: func() (domain.Shipment, error) { return uc.Get(usecase.Actor{}, 1) }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := c.call()
+			if err != usecase.Forbidden {
+				t.Errorf("expected '%s' error but got '%v'", usecase.Forbidden, err)
+			}
+			if !s.IsNil() {
+				t.Errorf("expected shipment to be nil but got %#v", s)
+			}
+		})
+	}
+}
+
+func TestShipmentUseCase_WithPolicy(t *testing.T) {
+	p, _ := usecase.ParsePolicy([]byte("roles:\n  driver: [create]\n"))
+	sequence := func() domain.ShipmentID {
+		return domain.ShipmentID(1)
+	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, nil)
+	save := func(*domain.Shipment) error {
+		return nil
+	}
+	uc := usecase.NewShipmentUseCase(save, getter, sequence).WithPolicy(p)
+
+	_, err := uc.Create(usecase.Actor{ID: "driver-1", Role: usecase.Driver}, "valid origin", "valid destination")
+	if err != nil {
+		t.Errorf("expected error to be nil but got '%s'", err)
+	}
+	_, err = uc.Create(admin, "valid origin", "valid destination")
+	if err != usecase.Forbidden {
+		t.Errorf("expected '%s' error but got '%v'", usecase.Forbidden, err)
+	}
+}
+
+func FuzzParsePolicy(f *testing.F) {
+	f.Add([]byte("roles: ["))
+	f.Add([]byte("roles: {}"))
+	f.Add([]byte("permissions:\n  driver: [deliver]\n"))
+	f.Add([]byte("roles:\n  driver: [deliver, teleport]\n"))
+	f.Add([]byte("roles:\n  driver: [deliver]\n  support: [cancel]\n"))
+
+	actions := []usecase.Action{
+		usecase.ActionView, usecase.ActionCreate, usecase.ActionHandle, usecase.ActionShip, usecase.ActionDeliver, usecase.ActionCancel,
+		usecase.ActionReturn, usecase.ActionReportAttempt, usecase.ActionDeclareCustoms, usecase.ActionHold,
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p, err := usecase.ParsePolicy(data)
+		if err != nil {
+			if err != usecase.InvalidPolicy {
+				t.Fatalf("expected '%s' error but got '%s'", usecase.InvalidPolicy, err)
+			}
+			return
+		}
+
+		encoded, err := yaml.Marshal(map[string]usecase.Policy{"roles": p})
+		if err != nil {
+			t.Fatalf("expected error to be nil but got '%s'", err)
+		}
+		decoded, err := usecase.ParsePolicy(encoded)
+		if err != nil {
+			t.Fatalf("expected re-encoded policy to parse but got '%s' for:\n%s", err, encoded)
+		}
+		for role := range p {
+			actor := usecase.Actor{ID: "actor", Role: role}
+			for _, a := range actions {
+				if p.Allows(actor, a) != decoded.Allows(actor, a) {
+					t.Errorf("expected role '%s' to keep its permission to '%s' after round trip", role, a)
+				}
+			}
+		}
+	})
+}
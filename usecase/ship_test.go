@@ -0,0 +1,136 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/carrier"
+	"github.com/facucachomeli/workshop-go-testing/carrier/fake"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
+)
+
+func handledGetter(t *testing.T) *usecasetest.Getter {
+	return usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().InState(domain.Handled).Build(), nil)
+}
+
+func fakeCarriers(c carrier.Carrier) *carrier.Registry {
+	r := carrier.NewRegistry()
+	r.Register("fake", c)
+	r.Default("fake")
+	return r
+}
+
+func TestShipmentUseCase_Handle_ShipmentCanNotBeHandled(t *testing.T) {
+	uc := usecase.NewShipmentUseCase(nil, handledGetter(t), nil)
+
+	s, err := uc.Handle(admin, domain.ShipmentID(1))
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.ShipmentCanNotBeHandled {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentCanNotBeHandled, err)
+	}
+	if s.State != domain.Handled {
+		t.Errorf("expected shipment to be Handled but got %s", s.State)
+	}
+}
+
+func TestShipmentUseCase_Handle_OK(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().Build(), nil)
+	var saved domain.Shipment
+	save := func(s *domain.Shipment) error {
+		saved = *s
+		return nil
+	}
+	uc := usecase.NewShipmentUseCase(save, getter, nil)
+
+	s, err := uc.Handle(admin, domain.ShipmentID(1))
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.State != domain.Handled || saved.State != domain.Handled {
+		t.Errorf("expected shipment to be saved as Handled but got %s", saved.State)
+	}
+}
+
+func TestShipmentUseCase_Ship_NoCarrierAvailable(t *testing.T) {
+	carriers := usecasetest.NewCarriers(t).ReturnsForAny("", nil, carrier.NoCarrierForRoute)
+	uc := usecase.NewShipmentUseCase(nil, handledGetter(t), nil).
+		WithCarriers(carriers)
+
+	s, err := uc.Ship(admin, domain.ShipmentID(1))
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.NoCarrierAvailable {
+		t.Errorf("expected '%s' error but got '%s'", usecase.NoCarrierAvailable, err)
+	}
+	if s.State != domain.Handled {
+		t.Errorf("expected shipment to be Handled but got %s", s.State)
+	}
+}
+
+func TestShipmentUseCase_Ship_CouldNotBookPickup(t *testing.T) {
+	c := fake.New("FK")
+	c.BookPickupFunc = func(domain.Shipment) (carrier.Booking, error) {
+		return carrier.Booking{}, errors.New("Pickup unavailable")
+	}
+	uc := usecase.NewShipmentUseCase(nil, handledGetter(t), nil).
+		WithCarriers(fakeCarriers(c))
+
+	s, err := uc.Ship(admin, domain.ShipmentID(1))
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.CouldNotBookPickup {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotBookPickup, err)
+	}
+	if s.State != domain.Handled {
+		t.Errorf("expected shipment to be Handled but got %s", s.State)
+	}
+}
+
+func TestShipmentUseCase_Ship_SaveErrorCancelsBooking(t *testing.T) {
+	c := fake.New("FK")
+	save := func(*domain.Shipment) error {
+		return errors.New("Save error")
+	}
+	uc := usecase.NewShipmentUseCase(save, handledGetter(t), nil).
+		WithCarriers(fakeCarriers(c))
+
+	_, err := uc.Ship(admin, domain.ShipmentID(1))
+	if err != usecase.CouldNotSaveShipment {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotSaveShipment, err)
+	}
+	if !c.Cancelled("FK00000001") {
+		t.Errorf("expected booking to be cancelled")
+	}
+}
+
+func TestShipmentUseCase_Ship_OK(t *testing.T) {
+	c := fake.New("FK")
+	var saved domain.Shipment
+	save := func(s *domain.Shipment) error {
+		saved = *s
+		return nil
+	}
+	uc := usecase.NewShipmentUseCase(save, handledGetter(t), nil).
+		WithCarriers(fakeCarriers(c))
+
+	s, err := uc.Ship(admin, domain.ShipmentID(1))
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.State != domain.Shipped {
+		t.Errorf("expected shipment to be Shipped but got %s", s.State)
+	}
+	if saved.Carrier != "fake" {
+		t.Errorf("expected Carrier to be 'fake' but got '%s'", saved.Carrier)
+	}
+	if saved.TrackingNumber != "FK00000001" {
+		t.Errorf("expected TrackingNumber to be 'FK00000001' but got '%s'", saved.TrackingNumber)
+	}
+	if _, ok := c.Booked(saved.TrackingNumber); !ok {
+		t.Errorf("expected pickup to be booked")
+	}
+}
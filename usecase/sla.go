@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+type slaSweepUseCase struct {
+	lister Lister
+	policy domain.SLAPolicy
+}
+
+type Lister interface {
+	List() ([]domain.Shipment, error)
+}
+
+func NewSLASweepUseCase(lister Lister, policy domain.SLAPolicy) slaSweepUseCase {
+	return slaSweepUseCase{lister, policy}
+}
+
+var CouldNotListShipments = errors.New("Could not list shipments")
+
+func (uc slaSweepUseCase) AtRisk(at time.Time) ([]domain.Shipment, error) {
+	return uc.sweep(at, domain.AtRisk)
+}
+
+func (uc slaSweepUseCase) Breached(at time.Time) ([]domain.Shipment, error) {
+	return uc.sweep(at, domain.Breached)
+}
+
+func (uc slaSweepUseCase) sweep(at time.Time, status domain.SLAStatus) ([]domain.Shipment, error) {
+	shipments, err := uc.lister.List()
+	if err != nil {
+		return nil, CouldNotListShipments
+	}
+
+	var found []domain.Shipment
+	for _, s := range shipments {
+		if uc.policy.Status(s, at) == status {
+			found = append(found, s)
+		}
+	}
+
+	return found, nil
+}
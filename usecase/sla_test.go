@@ -0,0 +1,53 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
+)
+
+func TestSLASweepUseCase_AtRisk_CouldNotListShipments(t *testing.T) {
+	lister := usecasetest.NewLister(t).Returns(nil, errors.New("List error"))
+	uc := usecase.NewSLASweepUseCase(lister, domain.DefaultSLAPolicy())
+
+	shipments, err := uc.AtRisk(time.Now())
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.CouldNotListShipments {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotListShipments, err)
+	}
+	if len(shipments) != 0 {
+		t.Errorf("expected no shipments but got %d", len(shipments))
+	}
+}
+
+func TestSLASweepUseCase_OK(t *testing.T) {
+	now := time.Date(2019, 10, 4, 10, 0, 0, 0, time.UTC)
+	lister := usecasetest.NewLister(t).Returns([]domain.Shipment{
+		{ID: 1, State: domain.Shipped, PromisedBy: now.Add(48 * time.Hour)},
+		{ID: 2, State: domain.Shipped, PromisedBy: now.Add(2 * time.Hour)},
+		{ID: 3, State: domain.Created, PromisedBy: now.Add(-2 * time.Hour)},
+		{ID: 4, State: domain.Cancelled, PromisedBy: now.Add(2 * time.Hour)},
+	}, nil)
+	uc := usecase.NewSLASweepUseCase(lister, domain.DefaultSLAPolicy())
+
+	atRisk, err := uc.AtRisk(now)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if len(atRisk) != 1 || atRisk[0].ID != 2 {
+		t.Errorf("expected only shipment 2 to be at risk but got %#v", atRisk)
+	}
+
+	breached, err := uc.Breached(now)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if len(breached) != 1 || breached[0].ID != 3 {
+		t.Errorf("expected only shipment 3 to be breached but got %#v", breached)
+	}
+}
@@ -3,7 +3,10 @@ package usecase
 import (
 	"errors"
 	"reflect"
+	"time"
 
+	"github.com/facucachomeli/workshop-go-testing/carrier"
+	"github.com/facucachomeli/workshop-go-testing/dangerousgoods"
 	"github.com/facucachomeli/workshop-go-testing/domain"
 )
 
@@ -11,37 +14,163 @@ type shipmentUseCase struct {
 	save     func(*domain.Shipment) error
 	getter   Getter
 	sequence func() domain.ShipmentID
+	publish  func(domain.Shipment)
+	now      func() time.Time
+	sla      domain.SLAPolicy
+	carriers Carriers
+	hazmat   DangerousGoods
+	fees     domain.FeeSchedule
+	refund   func(RefundInstruction) error
+	policy   Policy
+
+	maxDeliveryAttempts int
 }
 
 type Getter interface {
 	GetByID(domain.ShipmentID) (domain.Shipment, error)
 }
 
+type DangerousGoods interface {
+	Check(domain.Shipment) error
+}
+
+type Carriers interface {
+	ForRoute(domain.Route) (string, carrier.Carrier, error)
+}
+
 func NewShipmentUseCase(save func(*domain.Shipment) error, getter Getter, sequence func() domain.ShipmentID) shipmentUseCase {
-	return shipmentUseCase{save, getter, sequence}
+	return shipmentUseCase{
+		save:                save,
+		getter:              getter,
+		sequence:            sequence,
+		publish:             func(domain.Shipment) {},
+		now:                 time.Now,
+		sla:                 domain.DefaultSLAPolicy(),
+		hazmat:              dangerousgoods.NewEngine(dangerousgoods.DefaultRules()...),
+		fees:                domain.DefaultFeeSchedule(),
+		refund:              func(RefundInstruction) error { return nil },
+		policy:              DefaultPolicy(),
+		maxDeliveryAttempts: 3,
+	}
+}
+
+func (uc shipmentUseCase) WithPublisher(publish func(domain.Shipment)) shipmentUseCase {
+	uc.publish = publish
+	return uc
+}
+
+func (uc shipmentUseCase) WithClock(now func() time.Time) shipmentUseCase {
+	uc.now = now
+	return uc
+}
+
+func (uc shipmentUseCase) WithCarriers(carriers Carriers) shipmentUseCase {
+	uc.carriers = carriers
+	return uc
+}
+
+func (uc shipmentUseCase) WithDangerousGoods(hazmat DangerousGoods) shipmentUseCase {
+	uc.hazmat = hazmat
+	return uc
+}
+
+func (uc shipmentUseCase) WithSLAPolicy(p domain.SLAPolicy) shipmentUseCase {
+	uc.sla = p
+	return uc
+}
+
+func (uc shipmentUseCase) WithFeeSchedule(fees domain.FeeSchedule) shipmentUseCase {
+	uc.fees = fees
+	return uc
+}
+
+func (uc shipmentUseCase) WithRefunds(refund func(RefundInstruction) error) shipmentUseCase {
+	uc.refund = refund
+	return uc
+}
+
+func (uc shipmentUseCase) WithPolicy(p Policy) shipmentUseCase {
+	uc.policy = p
+	return uc
+}
+
+func (uc shipmentUseCase) WithMaxDeliveryAttempts(max int) shipmentUseCase {
+	uc.maxDeliveryAttempts = max
+	return uc
 }
 
 var CouldNotCreateShipment = errors.New("Could not create shipment")
 var CouldNotCheckExistingShipment = errors.New("Could not check existing shipment")
 var ShipmentAlreadyExists = errors.New("Shipment already exists")
 var ShipmentDoesNotExist = errors.New("Shipment does not exist")
+var ShipmentOnHold = errors.New("Shipment is on hold")
+var ShipmentCanNotBeHandled = errors.New("Shipment can not be handled")
+var ShipmentCanNotBeShipped = errors.New("Shipment can not be shipped")
+var IncompleteCustomsDeclaration = errors.New("Incomplete customs declaration")
+var NoCarrierAvailable = errors.New("No carrier available")
+var CouldNotBookPickup = errors.New("Could not book pickup")
 var ShipmentCanNotBeDelivered = errors.New("Shipement can not be delivered")
+var CouldNotSaveShipment = errors.New("Could not save shipment")
+var ShipmentCanNotBeReturned = errors.New("Shipment can not be returned")
+var InvalidProofOfDelivery = errors.New("Invalid proof of delivery")
+var ProofOfDeliveryNotFound = errors.New("Proof of delivery not found")
 
-func (uc shipmentUseCase) Create(origin string, destination string) (domain.Shipment, error) {
+type CreateOption func(*domain.Shipment)
+
+func WithServiceLevel(level domain.ServiceLevel) CreateOption {
+	return func(s *domain.Shipment) {
+		s.ServiceLevel = level
+	}
+}
+
+func WithCountries(origin string, destination string) CreateOption {
+	return func(s *domain.Shipment) {
+		s.OriginCountry = origin
+		s.DestinationCountry = destination
+	}
+}
+
+func WithParcels(parcels ...domain.Parcel) CreateOption {
+	return func(s *domain.Shipment) {
+		s.Parcels = append(s.Parcels, parcels...)
+	}
+}
+
+func (uc shipmentUseCase) Create(actor Actor, origin string, destination string, opts ...CreateOption) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionCreate) {
+		return domain.Shipment{}, Forbidden
+	}
 
 	s, err := domain.NewShipment(uc.sequence(), origin, destination)
 	if err != nil {
 		return domain.Shipment{}, CouldNotCreateShipment
 	}
 
+	s.ServiceLevel = domain.Standard
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if err := s.Create(); err != nil {
+		return domain.Shipment{}, CouldNotCreateShipment
+	}
+	if err := s.PromiseDelivery(uc.sla, uc.now()); err != nil {
+		return domain.Shipment{}, CouldNotCreateShipment
+	}
+	if err := uc.hazmat.Check(s); err != nil {
+		return domain.Shipment{}, err
+	}
+
 	if err := uc.canCreateShipment(s); err != nil {
 		return domain.Shipment{}, err
 	}
 
 	if err := uc.save(&s); err != nil {
-		return domain.Shipment{}, CouldNotCreateShipment
+		return domain.Shipment{}, CouldNotSaveShipment
 	}
 
+	uc.publish(s)
+
 	return s, nil
 }
 
@@ -58,7 +187,59 @@ func (uc shipmentUseCase) canCreateShipment(s domain.Shipment) error {
 	return nil
 }
 
-func (uc shipmentUseCase) Deliver(id domain.ShipmentID) (domain.Shipment, error) {
+func (uc shipmentUseCase) Get(actor Actor, id domain.ShipmentID) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionView) {
+		return domain.Shipment{}, Forbidden
+	}
+
+	s, err := uc.getter.GetByID(id)
+	if err != nil {
+		return domain.Shipment{}, CouldNotCheckExistingShipment
+	}
+
+	if s.IsNil() {
+		return domain.Shipment{}, ShipmentDoesNotExist
+	}
+
+	return s, nil
+}
+
+func (uc shipmentUseCase) Handle(actor Actor, id domain.ShipmentID) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionHandle) {
+		return domain.Shipment{}, Forbidden
+	}
+
+	s, err := uc.getter.GetByID(id)
+	if err != nil {
+		return domain.Shipment{}, CouldNotCheckExistingShipment
+	}
+
+	if s.IsNil() {
+		return domain.Shipment{}, ShipmentDoesNotExist
+	}
+
+	if s.IsOnHold() {
+		return s, ShipmentOnHold
+	}
+
+	if err := s.Handle(); err != nil {
+		return s, ShipmentCanNotBeHandled
+	}
+
+	if err := uc.save(&s); err != nil {
+		return domain.Shipment{}, CouldNotSaveShipment
+	}
+
+	uc.publish(s)
+
+	return s, nil
+}
+
+func (uc shipmentUseCase) Ship(actor Actor, id domain.ShipmentID) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionShip) {
+		return domain.Shipment{}, Forbidden
+	}
+
 	s, err := uc.getter.GetByID(id)
 	if err != nil {
 		return domain.Shipment{}, CouldNotCheckExistingShipment
@@ -68,10 +249,151 @@ func (uc shipmentUseCase) Deliver(id domain.ShipmentID) (domain.Shipment, error)
 		return domain.Shipment{}, ShipmentDoesNotExist
 	}
 
-	err = s.Deliver()
-	if err != nil && err != domain.ShipmentAlreadyDelivered {
+	if s.IsOnHold() {
+		return s, ShipmentOnHold
+	}
+
+	if err := s.CanShip(); err == domain.InvalidStateForShip {
+		return s, ShipmentCanNotBeShipped
+	} else if err != nil {
+		return s, IncompleteCustomsDeclaration
+	}
+	if err := uc.hazmat.Check(s); err != nil {
+		return s, err
+	}
+
+	if uc.carriers == nil {
+		return s, NoCarrierAvailable
+	}
+	name, c, err := uc.carriers.ForRoute(domain.Route{Origin: s.Origin, Destination: s.Destination})
+	if err != nil {
+		return s, NoCarrierAvailable
+	}
+
+	b, err := c.BookPickup(s)
+	if err != nil {
+		return s, CouldNotBookPickup
+	}
+
+	if err := s.Ship(name, b.TrackingNumber); err != nil {
+		c.Cancel(b.TrackingNumber)
+		return domain.Shipment{}, ShipmentCanNotBeShipped
+	}
+
+	if err := uc.save(&s); err != nil {
+		c.Cancel(b.TrackingNumber)
+		return domain.Shipment{}, CouldNotSaveShipment
+	}
+
+	uc.publish(s)
+
+	return s, nil
+}
+
+func (uc shipmentUseCase) Deliver(actor Actor, id domain.ShipmentID, proof domain.ProofOfDelivery) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionDeliver) {
+		return domain.Shipment{}, Forbidden
+	}
+
+	s, err := uc.getter.GetByID(id)
+	if err != nil {
+		return domain.Shipment{}, CouldNotCheckExistingShipment
+	}
+
+	if s.IsNil() {
+		return domain.Shipment{}, ShipmentDoesNotExist
+	}
+
+	if s.IsOnHold() {
+		return s, ShipmentOnHold
+	}
+
+	if err := proof.Validate(); err != nil {
+		return s, InvalidProofOfDelivery
+	}
+
+	err = s.Deliver(proof)
+	if err == domain.ShipmentAlreadyDelivered {
+		return s, nil
+	}
+	if err != nil {
 		return s, ShipmentCanNotBeDelivered
 	}
 
+	if err := uc.save(&s); err != nil {
+		return domain.Shipment{}, CouldNotSaveShipment
+	}
+
+	uc.publish(s)
+
 	return s, nil
 }
+
+func (uc shipmentUseCase) ProofOfDelivery(actor Actor, id domain.ShipmentID) (domain.ProofOfDelivery, error) {
+	if !uc.policy.Allows(actor, ActionView) {
+		return domain.ProofOfDelivery{}, Forbidden
+	}
+
+	s, err := uc.getter.GetByID(id)
+	if err != nil {
+		return domain.ProofOfDelivery{}, CouldNotCheckExistingShipment
+	}
+
+	if s.IsNil() {
+		return domain.ProofOfDelivery{}, ShipmentDoesNotExist
+	}
+
+	if s.Proof == nil {
+		return domain.ProofOfDelivery{}, ProofOfDeliveryNotFound
+	}
+
+	return *s.Proof, nil
+}
+
+func (uc shipmentUseCase) InitiateReturn(actor Actor, id domain.ShipmentID) (domain.Shipment, error) {
+	if !uc.policy.Allows(actor, ActionReturn) {
+		return domain.Shipment{}, Forbidden
+	}
+
+	s, err := uc.getter.GetByID(id)
+	if err != nil {
+		return domain.Shipment{}, CouldNotCheckExistingShipment
+	}
+
+	if s.IsNil() {
+		return domain.Shipment{}, ShipmentDoesNotExist
+	}
+
+	if s.IsOnHold() {
+		return domain.Shipment{}, ShipmentOnHold
+	}
+
+	r, err := s.Return(uc.sequence())
+	if err != nil {
+		return domain.Shipment{}, ShipmentCanNotBeReturned
+	}
+	if r.ServiceLevel == "" {
+		r.ServiceLevel = domain.Standard
+	}
+	if err := r.PromiseDelivery(uc.sla, uc.now()); err != nil {
+		return domain.Shipment{}, ShipmentCanNotBeReturned
+	}
+
+	if err := uc.canCreateShipment(r); err != nil {
+		return domain.Shipment{}, err
+	}
+
+	// The original is saved first: its version guards against concurrent
+	// returns, so a conflict leaves no return shipment behind.
+	if err := uc.save(&s); err != nil {
+		return domain.Shipment{}, CouldNotSaveShipment
+	}
+	if err := uc.save(&r); err != nil {
+		return domain.Shipment{}, CouldNotSaveShipment
+	}
+
+	uc.publish(s)
+	uc.publish(r)
+
+	return r, nil
+}
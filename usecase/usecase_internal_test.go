@@ -5,26 +5,14 @@ import (
 	"testing"
 
 	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
 )
 
-type getterMock struct {
-	mock func(domain.ShipmentID) (domain.Shipment, error)
-}
-
-func (m getterMock) GetByID(id domain.ShipmentID) (domain.Shipment, error) {
-	return m.mock(id)
-}
-
 func TestShipmentUseCase_CanCreateShipment_CouldNotCheckExistingShipment(t *testing.T) {
-	getter := getterMock{
-		mock: func(domain.ShipmentID) (domain.Shipment, error) {
-			return domain.Shipment{}, errors.New("Getter error")
-		},
-	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, errors.New("Getter error"))
 	uc := shipmentUseCase{
-		nil,
-		getter,
-		nil,
+		getter: getter,
 	}
 	s := domain.Shipment{}
 	err := uc.canCreateShipment(s)
@@ -39,21 +27,10 @@ func TestShipmentUseCase_CanCreateShipment_CouldNotCheckExistingShipment(t *test
 }
 
 func TestShipmentUseCase_CanCreateShipment_ShipmentAlreadyExists(t *testing.T) {
-	s := domain.Shipment{
-		domain.ShipmentID(1),
-		domain.Created,
-		"valid origin",
-		"valid destination",
-	}
-	getter := getterMock{
-		mock: func(domain.ShipmentID) (domain.Shipment, error) {
-			return s, nil
-		},
-	}
+	s := shipmenttest.AShipment().Build()
+	getter := usecasetest.NewGetter(t).ReturnsForAny(s, nil)
 	uc := shipmentUseCase{
-		nil,
-		getter,
-		nil,
+		getter: getter,
 	}
 	err := uc.canCreateShipment(s)
 	if err == nil {
@@ -68,15 +45,9 @@ func TestShipmentUseCase_CanCreateShipment_ShipmentAlreadyExists(t *testing.T) {
 
 func TestShipmentUseCase_CanCreateShipment_OK(t *testing.T) {
 	s := domain.Shipment{}
-	getter := getterMock{
-		mock: func(domain.ShipmentID) (domain.Shipment, error) {
-			return s, nil
-		},
-	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(s, nil)
 	uc := shipmentUseCase{
-		nil,
-		getter,
-		nil,
+		getter: getter,
 	}
 	err := uc.canCreateShipment(s)
 	if err != nil {
@@ -3,18 +3,15 @@ package usecase_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
 	"github.com/facucachomeli/workshop-go-testing/usecase"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
 )
 
-type getterMock struct {
-	mock func(domain.ShipmentID) (domain.Shipment, error)
-}
-
-func (m getterMock) GetByID(id domain.ShipmentID) (domain.Shipment, error) {
-	return m.mock(id)
-}
+var admin = usecase.Actor{ID: "admin-1", Role: usecase.Admin}
 
 func TestShipmentUseCase_Create_CouldNotCreateShipment(t *testing.T) {
 	sequence := func() domain.ShipmentID {
@@ -22,7 +19,7 @@ func TestShipmentUseCase_Create_CouldNotCreateShipment(t *testing.T) {
 	}
 	uc := usecase.NewShipmentUseCase(nil, nil, sequence)
 
-	s, err := uc.Create("", "")
+	s, err := uc.Create(admin, "", "")
 	if err == nil {
 		t.Errorf("expected error but found none")
 	} else if err != usecase.CouldNotCreateShipment {
@@ -37,14 +34,10 @@ func TestShipmentUseCase_Create_GetterError(t *testing.T) {
 	sequence := func() domain.ShipmentID {
 		return domain.ShipmentID(1)
 	}
-	getter := getterMock{
-		mock: func(domain.ShipmentID) (domain.Shipment, error) {
-			return domain.Shipment{}, errors.New("Getter error")
-		},
-	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, errors.New("Getter error"))
 	uc := usecase.NewShipmentUseCase(nil, getter, sequence)
 
-	s, err := uc.Create("valid origin", "valid destination")
+	s, err := uc.Create(admin, "valid origin", "valid destination")
 	if err == nil {
 		t.Errorf("expected error but found none")
 	} else if err != usecase.CouldNotCheckExistingShipment {
@@ -59,22 +52,18 @@ func TestShipmentUseCase_Create_SaveError(t *testing.T) {
 	sequence := func() domain.ShipmentID {
 		return domain.ShipmentID(1)
 	}
-	getter := getterMock{
-		mock: func(domain.ShipmentID) (domain.Shipment, error) {
-			return domain.Shipment{}, nil
-		},
-	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, nil)
 	save := func(*domain.Shipment) error {
 		return errors.New("Save error")
 	}
 
 	uc := usecase.NewShipmentUseCase(save, getter, sequence)
 
-	s, err := uc.Create("valid origin", "valid destination")
+	s, err := uc.Create(admin, "valid origin", "valid destination")
 	if err == nil {
 		t.Errorf("expected error but found none")
-	} else if err != usecase.CouldNotCreateShipment {
-		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotCreateShipment, err)
+	} else if err != usecase.CouldNotSaveShipment {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotSaveShipment, err)
 	}
 	if !s.IsNil() {
 		t.Errorf("expected shipment to be nil but got %#v", s)
@@ -88,18 +77,14 @@ func TestShipmentUseCase_Create_OK(t *testing.T) {
 	sequence := func() domain.ShipmentID {
 		return id
 	}
-	getter := getterMock{
-		mock: func(domain.ShipmentID) (domain.Shipment, error) {
-			return domain.Shipment{}, nil
-		},
-	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, nil)
 	save := func(*domain.Shipment) error {
 		return nil
 	}
 
 	uc := usecase.NewShipmentUseCase(save, getter, sequence)
 
-	s, err := uc.Create(origin, destination)
+	s, err := uc.Create(admin, origin, destination)
 	if err != nil {
 		t.Fatalf("expected error to be nil but got '%s'", err)
 	}
@@ -115,17 +100,90 @@ func TestShipmentUseCase_Create_OK(t *testing.T) {
 	if s.Destination != destination {
 		t.Errorf("expected ID to be '%v' but got '%v'", destination, s.Destination)
 	}
+	if s.State != domain.Created {
+		t.Errorf("expected State to be '%v' but got '%v'", domain.Created, s.State)
+	}
 }
 
-func TestShipmentUseCase_Deliver_CouldNotCheckExistingShipment(t *testing.T) {
-	getter := getterMock{
-		mock: func(domain.ShipmentID) (domain.Shipment, error) {
-			return domain.Shipment{}, errors.New("Get error")
-		},
+func TestShipmentUseCase_Create_InvalidServiceLevel(t *testing.T) {
+	sequence := func() domain.ShipmentID {
+		return domain.ShipmentID(1)
+	}
+	uc := usecase.NewShipmentUseCase(nil, nil, sequence)
+
+	s, err := uc.Create(admin, "valid origin", "valid destination", usecase.WithServiceLevel(domain.ServiceLevel("Teleport")))
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.CouldNotCreateShipment {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotCreateShipment, err)
+	}
+	if !s.IsNil() {
+		t.Errorf("expected shipment to be nil but got %#v", s)
+	}
+}
+
+func TestShipmentUseCase_Create_PromisesDelivery(t *testing.T) {
+	now := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	sequence := func() domain.ShipmentID {
+		return domain.ShipmentID(1)
+	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, nil)
+	save := func(*domain.Shipment) error {
+		return nil
+	}
+	uc := usecase.NewShipmentUseCase(save, getter, sequence).
+		WithClock(func() time.Time { return now })
+
+	s, err := uc.Create(admin, "valid origin", "valid destination", usecase.WithServiceLevel(domain.Express))
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if s.ServiceLevel != domain.Express {
+		t.Errorf("expected ServiceLevel to be '%v' but got '%v'", domain.Express, s.ServiceLevel)
+	}
+	if !s.CreatedAt.Equal(now) {
+		t.Errorf("expected CreatedAt to be '%v' but got '%v'", now, s.CreatedAt)
+	}
+	if expected := now.Add(24 * time.Hour); !s.PromisedBy.Equal(expected) {
+		t.Errorf("expected PromisedBy to be '%v' but got '%v'", expected, s.PromisedBy)
+	}
+}
+
+func TestShipmentUseCase_Get_ShipmentDoesNotExist(t *testing.T) {
+	getter := usecasetest.NewGetter(t).Returns(1, domain.Shipment{}, nil)
+	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+	s, err := uc.Get(admin, domain.ShipmentID(1))
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.ShipmentDoesNotExist {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentDoesNotExist, err)
 	}
+	if !s.IsNil() {
+		t.Errorf("expected shipment to be nil but got %#v", s)
+	}
+}
+
+func TestShipmentUseCase_Get_OK(t *testing.T) {
+	stored := shipmenttest.AShipment().InState(domain.Shipped).Build()
+	getter := usecasetest.NewGetter(t).Returns(stored.ID, stored, nil)
 	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+	driver := usecase.Actor{ID: "driver-1", Role: usecase.Driver}
 
-	s, err := uc.Deliver(domain.ShipmentID(1))
+	s, err := uc.Get(driver, stored.ID)
+	if err != nil {
+		t.Errorf("expected error to be nil but got '%s'", err)
+	}
+	if s.State != domain.Shipped {
+		t.Errorf("expected shipment to be Shipped but got %s", s.State)
+	}
+}
+
+func TestShipmentUseCase_Deliver_CouldNotCheckExistingShipment(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, errors.New("Get error"))
+	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+	s, err := uc.Deliver(admin, domain.ShipmentID(1), shipmenttest.ValidProof())
 	if err == nil {
 		t.Errorf("expected error but found none")
 	} else if err != usecase.CouldNotCheckExistingShipment {
@@ -137,14 +195,10 @@ func TestShipmentUseCase_Deliver_CouldNotCheckExistingShipment(t *testing.T) {
 }
 
 func TestShipmentUseCase_Deliver_ShipmentDoesNotExist(t *testing.T) {
-	getter := getterMock{
-		mock: func(domain.ShipmentID) (domain.Shipment, error) {
-			return domain.Shipment{}, nil
-		},
-	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, nil)
 	uc := usecase.NewShipmentUseCase(nil, getter, nil)
 
-	s, err := uc.Deliver(domain.ShipmentID(1))
+	s, err := uc.Deliver(admin, domain.ShipmentID(1), shipmenttest.ValidProof())
 	if err == nil {
 		t.Errorf("expected error but found none")
 	} else if err != usecase.ShipmentDoesNotExist {
@@ -156,20 +210,10 @@ func TestShipmentUseCase_Deliver_ShipmentDoesNotExist(t *testing.T) {
 }
 
 func TestShipmentUseCase_Deliver_ShipmentCanNotBeDelivered(t *testing.T) {
-	getter := getterMock{
-		mock: func(domain.ShipmentID) (domain.Shipment, error) {
-			s := domain.Shipment{
-				domain.ShipmentID(1),
-				domain.Created,
-				"valid origin",
-				"valid destination",
-			}
-			return s, nil
-		},
-	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().Build(), nil)
 	uc := usecase.NewShipmentUseCase(nil, getter, nil)
 
-	s, err := uc.Deliver(domain.ShipmentID(1))
+	s, err := uc.Deliver(admin, domain.ShipmentID(1), shipmenttest.ValidProof())
 	if err == nil {
 		t.Errorf("expected error but found none")
 	} else if err != usecase.ShipmentCanNotBeDelivered {
@@ -187,24 +231,14 @@ func TestShipmentUseCase_Deliver_OK(t *testing.T) {
 	sequence := func() domain.ShipmentID {
 		return id
 	}
-	getter := getterMock{
-		mock: func(domain.ShipmentID) (domain.Shipment, error) {
-			s := domain.Shipment{
-				domain.ShipmentID(1),
-				domain.Shipped,
-				"valid origin",
-				"valid destination",
-			}
-			return s, nil
-		},
-	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().InState(domain.Shipped).Build(), nil)
 	save := func(*domain.Shipment) error {
 		return nil
 	}
 
 	uc := usecase.NewShipmentUseCase(save, getter, sequence)
 
-	s, err := uc.Deliver(id)
+	s, err := uc.Deliver(admin, id, shipmenttest.ValidProof())
 	if err != nil {
 		t.Fatalf("expected error to be nil but got '%s'", err)
 	}
@@ -222,6 +256,223 @@ func TestShipmentUseCase_Deliver_OK(t *testing.T) {
 	}
 }
 
+func TestShipmentUseCase_Create_Publishes(t *testing.T) {
+	sequence := usecasetest.NewSequence(t, 1)
+	getter := usecasetest.NewGetter(t).
+		Returns(1, domain.Shipment{}, nil).
+		ExpectCalled(1, 1)
+	saver := usecasetest.NewSaver(t).ExpectCalled(1)
+	publisher := usecasetest.NewPublisher(t).ExpectCalled(1)
+
+	uc := usecase.NewShipmentUseCase(saver.Save, getter, sequence.Next).WithPublisher(publisher.Publish)
+
+	s, err := uc.Create(admin, "valid origin", "valid destination")
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if saver.Last().ID != s.ID {
+		t.Errorf("expected saved ID to be '%v' but got '%v'", s.ID, saver.Last().ID)
+	}
+	if published := publisher.Published(); published[0].ID != s.ID {
+		t.Errorf("expected published ID to be '%v' but got '%v'", s.ID, published[0].ID)
+	}
+}
+
+func TestShipmentUseCase_Deliver_CouldNotSaveShipment(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().InState(domain.Shipped).Build(), nil)
+	saver := usecasetest.NewSaver(t).Fails(errors.New("Save error")).ExpectCalled(1)
+	publisher := usecasetest.NewPublisher(t)
+	uc := usecase.NewShipmentUseCase(saver.Save, getter, nil).WithPublisher(publisher.Publish)
+
+	s, err := uc.Deliver(admin, domain.ShipmentID(1), shipmenttest.ValidProof())
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.CouldNotSaveShipment {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotSaveShipment, err)
+	}
+	if !s.IsNil() {
+		t.Errorf("expected shipment to be nil but got %#v", s)
+	}
+}
+
+func TestShipmentUseCase_Deliver_Publishes(t *testing.T) {
+	getter := usecasetest.NewGetter(t).
+		Returns(7, shipmenttest.AShipment().WithID(7).InState(domain.Shipped).Build(), nil).
+		ExpectCalled(7, 1)
+	saver := usecasetest.NewSaver(t).ExpectSaved(7, 1)
+	publisher := usecasetest.NewPublisher(t).ExpectCalled(1)
+	uc := usecase.NewShipmentUseCase(saver.Save, getter, nil).WithPublisher(publisher.Publish)
+
+	_, err := uc.Deliver(admin, domain.ShipmentID(7), shipmenttest.ValidProof())
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if saved := saver.Last(); saved.State != domain.Delivered {
+		t.Errorf("expected saved shipment to be Delivered but got %s", saved.State)
+	}
+	if published := publisher.Published(); published[0].State != domain.Delivered {
+		t.Errorf("expected published shipment to be Delivered but got %s", published[0].State)
+	}
+}
+
+func TestShipmentUseCase_InitiateReturn_ShipmentDoesNotExist(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(domain.Shipment{}, nil)
+	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+	r, err := uc.InitiateReturn(admin, domain.ShipmentID(1))
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.ShipmentDoesNotExist {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentDoesNotExist, err)
+	}
+	if !r.IsNil() {
+		t.Errorf("expected shipment to be nil but got %#v", r)
+	}
+}
+
+func TestShipmentUseCase_InitiateReturn_ShipmentCanNotBeReturned(t *testing.T) {
+	sequence := func() domain.ShipmentID {
+		return domain.ShipmentID(2)
+	}
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().InState(domain.Shipped).Build(), nil)
+	uc := usecase.NewShipmentUseCase(nil, getter, sequence)
+
+	r, err := uc.InitiateReturn(admin, domain.ShipmentID(1))
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.ShipmentCanNotBeReturned {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ShipmentCanNotBeReturned, err)
+	}
+	if !r.IsNil() {
+		t.Errorf("expected shipment to be nil but got %#v", r)
+	}
+}
+
+func TestShipmentUseCase_InitiateReturn_CouldNotSaveOriginal(t *testing.T) {
+	original := shipmenttest.AShipment().InState(domain.Delivered).Build()
+	sequence := func() domain.ShipmentID {
+		return domain.ShipmentID(2)
+	}
+	getter := usecasetest.NewGetter(t).
+		Returns(original.ID, original, nil).
+		Returns(2, domain.Shipment{}, nil)
+	saver := usecasetest.NewSaver(t).
+		Fails(errors.New("Shipment was modified concurrently")).
+		ExpectSaved(original.ID, 1)
+	uc := usecase.NewShipmentUseCase(saver.Save, getter, sequence)
+
+	r, err := uc.InitiateReturn(admin, original.ID)
+	if err != usecase.CouldNotSaveShipment {
+		t.Errorf("expected '%s' error but got '%s'", usecase.CouldNotSaveShipment, err)
+	}
+	if !r.IsNil() {
+		t.Errorf("expected shipment to be nil but got %#v", r)
+	}
+}
+
+func TestShipmentUseCase_InitiateReturn_OK(t *testing.T) {
+	original := shipmenttest.AShipment().InState(domain.Delivered).Build()
+	sequence := func() domain.ShipmentID {
+		return domain.ShipmentID(2)
+	}
+	getter := usecasetest.NewGetter(t).
+		Returns(original.ID, original, nil).
+		Returns(2, domain.Shipment{}, nil)
+	saved := map[domain.ShipmentID]domain.Shipment{}
+	save := func(s *domain.Shipment) error {
+		saved[s.ID] = *s
+		return nil
+	}
+
+	uc := usecase.NewShipmentUseCase(save, getter, sequence)
+
+	r, err := uc.InitiateReturn(admin, original.ID)
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if r.ID != 2 {
+		t.Errorf("expected ID to be '2' but got '%v'", r.ID)
+	}
+	if r.ReturnOf != original.ID {
+		t.Errorf("expected ReturnOf to be '%v' but got '%v'", original.ID, r.ReturnOf)
+	}
+	if r.Origin != original.Destination || r.Destination != original.Origin {
+		t.Errorf("expected origin and destination to be swapped but got %#v", r)
+	}
+	if saved[original.ID].State != domain.Returned {
+		t.Errorf("expected original shipment to be Returned but got %s", saved[original.ID].State)
+	}
+	if saved[r.ID].State != domain.Created {
+		t.Errorf("expected return shipment to be Created but got %s", saved[r.ID].State)
+	}
+}
+
+func TestShipmentUseCase_Deliver_InvalidProofOfDelivery(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().InState(domain.Shipped).Build(), nil)
+	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+	s, err := uc.Deliver(admin, domain.ShipmentID(1), domain.ProofOfDelivery{})
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.InvalidProofOfDelivery {
+		t.Errorf("expected '%s' error but got '%s'", usecase.InvalidProofOfDelivery, err)
+	}
+	if s.State != domain.Shipped {
+		t.Errorf("expected shipment to be Shipped but got %s", s.State)
+	}
+}
+
+func TestShipmentUseCase_ProofOfDelivery_Forbidden(t *testing.T) {
+	uc := usecase.NewShipmentUseCase(nil, usecasetest.NewGetter(t), nil)
+
+	p, err := uc.ProofOfDelivery(usecase.Actor{}, domain.ShipmentID(1))
+	if err != usecase.Forbidden {
+		t.Errorf("expected '%s' error but got '%v'", usecase.Forbidden, err)
+	}
+	if p.RecipientName != "" {
+		t.Errorf("expected no proof but got %#v", p)
+	}
+}
+
+func TestShipmentUseCase_ProofOfDelivery_ProofOfDeliveryNotFound(t *testing.T) {
+	getter := usecasetest.NewGetter(t).ReturnsForAny(shipmenttest.AShipment().InState(domain.Shipped).Build(), nil)
+	uc := usecase.NewShipmentUseCase(nil, getter, nil)
+
+	_, err := uc.ProofOfDelivery(admin, domain.ShipmentID(1))
+	if err == nil {
+		t.Errorf("expected error but found none")
+	} else if err != usecase.ProofOfDeliveryNotFound {
+		t.Errorf("expected '%s' error but got '%s'", usecase.ProofOfDeliveryNotFound, err)
+	}
+}
+
+func TestShipmentUseCase_ProofOfDelivery_OK(t *testing.T) {
+	stored := shipmenttest.AShipment().InState(domain.Shipped).Build()
+	getter := usecasetest.NewGetter(t).ReturnsFunc(func(domain.ShipmentID) (domain.Shipment, error) {
+		return stored, nil
+	})
+	save := func(s *domain.Shipment) error {
+		stored = *s
+		return nil
+	}
+	uc := usecase.NewShipmentUseCase(save, getter, nil)
+
+	if _, err := uc.Deliver(admin, domain.ShipmentID(1), shipmenttest.ValidProof()); err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+
+	p, err := uc.ProofOfDelivery(admin, domain.ShipmentID(1))
+	if err != nil {
+		t.Fatalf("expected error to be nil but got '%s'", err)
+	}
+	if p.RecipientName != shipmenttest.ValidProof().RecipientName {
+		t.Errorf("expected RecipientName to be '%v' but got '%v'", shipmenttest.ValidProof().RecipientName, p.RecipientName)
+	}
+	if !p.DeliveredAt.Equal(shipmenttest.ValidProof().DeliveredAt) {
+		t.Errorf("expected DeliveredAt to be '%v' but got '%v'", shipmenttest.ValidProof().DeliveredAt, p.DeliveredAt)
+	}
+}
+
 // getter := getterMock{
 // 	mock: func(domain.ShipmentID) (domain.Shipment, error) {
 // 		s, _ := domain.NewShipment(domain.ShipmentID(1), "valid origin", "valid destination")
@@ -0,0 +1,510 @@
+// Package usecasetest provides recording test doubles for the ports the use
+// cases depend on. Every double fails the test it was created with on calls
+// it was not told to expect, and checks registered expectations when the
+// test finishes.
+//
+// It only depends on domain and carrier so the usecase package can use it
+// from both its internal and external tests.
+package usecasetest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/carrier"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+type response struct {
+	shipment domain.Shipment
+	err      error
+}
+
+type Getter struct {
+	t         testing.TB
+	mu        sync.Mutex
+	responses map[domain.ShipmentID]response
+	fallback  func(domain.ShipmentID) (domain.Shipment, error)
+	calls     []domain.ShipmentID
+}
+
+func NewGetter(t testing.TB) *Getter {
+	return &Getter{t: t, responses: map[domain.ShipmentID]response{}}
+}
+
+// Returns stubs the answer for id. Lookups of ids without a stub fail the
+// test unless ReturnsForAny or ReturnsFunc was configured.
+func (g *Getter) Returns(id domain.ShipmentID, s domain.Shipment, err error) *Getter {
+	g.responses[id] = response{s, err}
+	return g
+}
+
+func (g *Getter) ReturnsForAny(s domain.Shipment, err error) *Getter {
+	return g.ReturnsFunc(func(domain.ShipmentID) (domain.Shipment, error) {
+		return s, err
+	})
+}
+
+// ReturnsFunc answers lookups without a stub by calling f, which is useful
+// when the answer depends on what the test saved in between.
+func (g *Getter) ReturnsFunc(f func(domain.ShipmentID) (domain.Shipment, error)) *Getter {
+	g.fallback = f
+	return g
+}
+
+func (g *Getter) GetByID(id domain.ShipmentID) (domain.Shipment, error) {
+	g.t.Helper()
+
+	g.mu.Lock()
+	g.calls = append(g.calls, id)
+	r, ok := g.responses[id]
+	fallback := g.fallback
+	g.mu.Unlock()
+
+	if ok {
+		return r.shipment, r.err
+	}
+	if fallback != nil {
+		return fallback(id)
+	}
+
+	g.t.Errorf("unexpected call to GetByID(%d)", id)
+	return domain.Shipment{}, nil
+}
+
+func (g *Getter) Calls() []domain.ShipmentID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]domain.ShipmentID(nil), g.calls...)
+}
+
+// ExpectCalled fails the test at cleanup unless GetByID was called with id
+// exactly times times.
+func (g *Getter) ExpectCalled(id domain.ShipmentID, times int) *Getter {
+	g.t.Helper()
+	g.t.Cleanup(func() {
+		g.t.Helper()
+		if actual := count(g.Calls(), id); actual != times {
+			g.t.Errorf("expected GetByID(%d) to be called %d times but got %d", id, times, actual)
+		}
+	})
+	return g
+}
+
+func count(calls []domain.ShipmentID, id domain.ShipmentID) int {
+	n := 0
+	for _, c := range calls {
+		if c == id {
+			n++
+		}
+	}
+	return n
+}
+
+// Saver fails the test on every call to Save until it is told which calls
+// to expect, through ExpectCalled, ExpectSaved or AcceptsAny.
+type Saver struct {
+	t     testing.TB
+	mu    sync.Mutex
+	err   error
+	any   bool
+	times int
+	ids   map[domain.ShipmentID]int
+	saved []domain.Shipment
+}
+
+func NewSaver(t testing.TB) *Saver {
+	return &Saver{t: t, times: -1, ids: map[domain.ShipmentID]int{}}
+}
+
+func (s *Saver) Fails(err error) *Saver {
+	s.err = err
+	return s
+}
+
+// AcceptsAny lets Save be called any number of times with any shipment.
+func (s *Saver) AcceptsAny() *Saver {
+	s.any = true
+	return s
+}
+
+func (s *Saver) Save(shipment *domain.Shipment) error {
+	s.t.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.expects(shipment.ID) {
+		s.t.Errorf("unexpected call to Save(%#v)", *shipment)
+	}
+	s.saved = append(s.saved, *shipment)
+
+	return s.err
+}
+
+func (s *Saver) expects(id domain.ShipmentID) bool {
+	if s.any || len(s.saved) < s.times {
+		return true
+	}
+
+	n := 0
+	for _, saved := range s.saved {
+		if saved.ID == id {
+			n++
+		}
+	}
+
+	return n < s.ids[id]
+}
+
+func (s *Saver) Saved() []domain.Shipment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]domain.Shipment(nil), s.saved...)
+}
+
+// Last returns the latest shipment passed to Save, failing the test if there
+// was none.
+func (s *Saver) Last() domain.Shipment {
+	s.t.Helper()
+
+	saved := s.Saved()
+	if len(saved) == 0 {
+		s.t.Fatalf("expected a shipment to be saved but none was")
+	}
+
+	return saved[len(saved)-1]
+}
+
+// ExpectCalled allows times calls to Save with any shipment and fails the
+// test at cleanup unless there were exactly that many.
+func (s *Saver) ExpectCalled(times int) *Saver {
+	s.t.Helper()
+	s.times = times
+	s.t.Cleanup(func() {
+		s.t.Helper()
+		if actual := len(s.Saved()); actual != times {
+			s.t.Errorf("expected Save to be called %d times but got %d", times, actual)
+		}
+	})
+	return s
+}
+
+// ExpectSaved allows times calls to Save with shipment id and fails the
+// test at cleanup unless there were exactly that many.
+func (s *Saver) ExpectSaved(id domain.ShipmentID, times int) *Saver {
+	s.t.Helper()
+	s.ids[id] = times
+	s.t.Cleanup(func() {
+		s.t.Helper()
+		n := 0
+		for _, saved := range s.Saved() {
+			if saved.ID == id {
+				n++
+			}
+		}
+		if n != times {
+			s.t.Errorf("expected Save(%d) to be called %d times but got %d", id, times, n)
+		}
+	})
+	return s
+}
+
+type Sequence struct {
+	t     testing.TB
+	mu    sync.Mutex
+	ids   []domain.ShipmentID
+	calls int
+}
+
+// NewSequence hands out ids in order. Asking for more ids than given fails
+// the test.
+func NewSequence(t testing.TB, ids ...domain.ShipmentID) *Sequence {
+	return &Sequence{t: t, ids: ids}
+}
+
+func (s *Sequence) Next() domain.ShipmentID {
+	s.t.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if s.calls > len(s.ids) {
+		s.t.Errorf("unexpected call %d to Next, only %d ids were expected", s.calls, len(s.ids))
+		return 0
+	}
+
+	return s.ids[s.calls-1]
+}
+
+func (s *Sequence) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
+
+// Publisher fails the test on every call to Publish until it is told how
+// many to expect through ExpectCalled or AcceptsAny.
+type Publisher struct {
+	t         testing.TB
+	mu        sync.Mutex
+	any       bool
+	times     int
+	published []domain.Shipment
+}
+
+func NewPublisher(t testing.TB) *Publisher {
+	return &Publisher{t: t}
+}
+
+// AcceptsAny lets Publish be called any number of times.
+func (p *Publisher) AcceptsAny() *Publisher {
+	p.any = true
+	return p
+}
+
+func (p *Publisher) Publish(s domain.Shipment) {
+	p.t.Helper()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.any && len(p.published) >= p.times {
+		p.t.Errorf("unexpected call to Publish(%#v)", s)
+	}
+	p.published = append(p.published, s)
+}
+
+func (p *Publisher) Published() []domain.Shipment {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]domain.Shipment(nil), p.published...)
+}
+
+// ExpectCalled allows times calls to Publish and fails the test at cleanup
+// unless there were exactly that many.
+func (p *Publisher) ExpectCalled(times int) *Publisher {
+	p.t.Helper()
+	p.times = times
+	p.t.Cleanup(func() {
+		p.t.Helper()
+		if actual := len(p.Published()); actual != times {
+			p.t.Errorf("expected Publish to be called %d times but got %d", times, actual)
+		}
+	})
+	return p
+}
+
+// Lister fails the test on calls to List until Returns or ReturnsFunc stubs
+// the answer.
+type Lister struct {
+	t     testing.TB
+	mu    sync.Mutex
+	list  func() ([]domain.Shipment, error)
+	calls int
+}
+
+func NewLister(t testing.TB) *Lister {
+	return &Lister{t: t}
+}
+
+func (l *Lister) Returns(shipments []domain.Shipment, err error) *Lister {
+	return l.ReturnsFunc(func() ([]domain.Shipment, error) {
+		return append([]domain.Shipment(nil), shipments...), err
+	})
+}
+
+// ReturnsFunc answers List by calling f, which is useful when the answer
+// depends on what the test saved in between.
+func (l *Lister) ReturnsFunc(f func() ([]domain.Shipment, error)) *Lister {
+	l.list = f
+	return l
+}
+
+func (l *Lister) List() ([]domain.Shipment, error) {
+	l.t.Helper()
+
+	l.mu.Lock()
+	l.calls++
+	list := l.list
+	l.mu.Unlock()
+
+	if list == nil {
+		l.t.Errorf("unexpected call to List()")
+		return nil, nil
+	}
+
+	return list()
+}
+
+func (l *Lister) Calls() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.calls
+}
+
+type carrierResponse struct {
+	name    string
+	carrier carrier.Carrier
+	err     error
+}
+
+type Carriers struct {
+	t         testing.TB
+	mu        sync.Mutex
+	responses map[domain.Route]carrierResponse
+	fallback  *carrierResponse
+	calls     []domain.Route
+}
+
+func NewCarriers(t testing.TB) *Carriers {
+	return &Carriers{t: t, responses: map[domain.Route]carrierResponse{}}
+}
+
+// Returns stubs the carrier picked for route. Routes without a stub fail the
+// test unless ReturnsForAny was configured.
+func (c *Carriers) Returns(route domain.Route, name string, cr carrier.Carrier, err error) *Carriers {
+	c.responses[route] = carrierResponse{name, cr, err}
+	return c
+}
+
+func (c *Carriers) ReturnsForAny(name string, cr carrier.Carrier, err error) *Carriers {
+	c.fallback = &carrierResponse{name, cr, err}
+	return c
+}
+
+func (c *Carriers) ForRoute(route domain.Route) (string, carrier.Carrier, error) {
+	c.t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, route)
+	r, ok := c.responses[route]
+	if !ok && c.fallback != nil {
+		r, ok = *c.fallback, true
+	}
+	if !ok {
+		c.t.Errorf("unexpected call to ForRoute(%#v)", route)
+		return "", nil, carrier.NoCarrierForRoute
+	}
+
+	return r.name, r.carrier, r.err
+}
+
+func (c *Carriers) Calls() []domain.Route {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]domain.Route(nil), c.calls...)
+}
+
+// DangerousGoods fails the test on calls to Check until Returns stubs the
+// answer.
+type DangerousGoods struct {
+	t       testing.TB
+	mu      sync.Mutex
+	stubbed bool
+	err     error
+	checked []domain.Shipment
+}
+
+func NewDangerousGoods(t testing.TB) *DangerousGoods {
+	return &DangerousGoods{t: t}
+}
+
+// Returns makes every check answer err, nil to let every shipment through.
+func (d *DangerousGoods) Returns(err error) *DangerousGoods {
+	d.stubbed = true
+	d.err = err
+	return d
+}
+
+func (d *DangerousGoods) Check(s domain.Shipment) error {
+	d.t.Helper()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.checked = append(d.checked, s)
+	if !d.stubbed {
+		d.t.Errorf("unexpected call to Check(%#v)", s)
+		return nil
+	}
+
+	return d.err
+}
+
+func (d *DangerousGoods) Checked() []domain.Shipment {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]domain.Shipment(nil), d.checked...)
+}
+
+// Refunds fails the test on every call to Refund until it is told how many
+// to expect through ExpectCalled or AcceptsAny.
+type Refunds struct {
+	t         testing.TB
+	mu        sync.Mutex
+	err       error
+	any       bool
+	times     int
+	requested []domain.RefundInstruction
+}
+
+func NewRefunds(t testing.TB) *Refunds {
+	return &Refunds{t: t}
+}
+
+func (r *Refunds) Fails(err error) *Refunds {
+	r.err = err
+	return r
+}
+
+// AcceptsAny lets Refund be called any number of times.
+func (r *Refunds) AcceptsAny() *Refunds {
+	r.any = true
+	return r
+}
+
+func (r *Refunds) Refund(instruction domain.RefundInstruction) error {
+	r.t.Helper()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.any && len(r.requested) >= r.times {
+		r.t.Errorf("unexpected call to Refund(%#v)", instruction)
+	}
+	r.requested = append(r.requested, instruction)
+
+	return r.err
+}
+
+func (r *Refunds) Requested() []domain.RefundInstruction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]domain.RefundInstruction(nil), r.requested...)
+}
+
+// ExpectCalled allows times calls to Refund and fails the test at cleanup
+// unless there were exactly that many.
+func (r *Refunds) ExpectCalled(times int) *Refunds {
+	r.t.Helper()
+	r.times = times
+	r.t.Cleanup(func() {
+		r.t.Helper()
+		if actual := len(r.Requested()); actual != times {
+			r.t.Errorf("expected Refund to be called %d times but got %d", times, actual)
+		}
+	})
+	return r
+}
@@ -0,0 +1,270 @@
+package usecasetest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/carrier"
+	"github.com/facucachomeli/workshop-go-testing/carrier/fake"
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/usecase/usecasetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder stands in for *testing.T so failures reported by the doubles can
+// be asserted on instead of failing this test.
+type recorder struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestGetter_Returns(t *testing.T) {
+	r := &recorder{}
+	shipment := domain.Shipment{ID: 7, State: domain.Created}
+	getter := usecasetest.NewGetter(r).Returns(7, shipment, nil)
+
+	s, err := getter.GetByID(7)
+
+	assert.Nil(t, err)
+	assert.Equal(t, shipment, s)
+	assert.Equal(t, []domain.ShipmentID{7}, getter.Calls())
+	assert.Empty(t, r.errors)
+}
+
+func TestGetter_UnexpectedCall(t *testing.T) {
+	r := &recorder{}
+	getter := usecasetest.NewGetter(r).Returns(7, domain.Shipment{ID: 7}, nil)
+
+	s, _ := getter.GetByID(8)
+
+	assert.True(t, s.IsNil(), "expected shipment to be nil but got %#v", s)
+	assert.Equal(t, []string{"unexpected call to GetByID(8)"}, r.errors)
+}
+
+func TestGetter_ReturnsForAny(t *testing.T) {
+	r := &recorder{}
+	getter := usecasetest.NewGetter(r).ReturnsForAny(domain.Shipment{}, errors.New("Get error"))
+
+	_, err := getter.GetByID(3)
+
+	assert.EqualError(t, err, "Get error")
+	assert.Empty(t, r.errors)
+}
+
+func TestGetter_ExpectCalled(t *testing.T) {
+	r := &recorder{}
+	getter := usecasetest.NewGetter(r).ReturnsForAny(domain.Shipment{}, nil).ExpectCalled(7, 1)
+	getter.GetByID(7)
+	getter.GetByID(7)
+
+	r.finish()
+
+	assert.Equal(t, []string{"expected GetByID(7) to be called 1 times but got 2"}, r.errors)
+}
+
+func TestSaver(t *testing.T) {
+	r := &recorder{}
+	saver := usecasetest.NewSaver(r).Fails(errors.New("Save error")).ExpectCalled(1)
+
+	err := saver.Save(&domain.Shipment{ID: 1, State: domain.Handled})
+	r.finish()
+
+	assert.EqualError(t, err, "Save error")
+	assert.Equal(t, domain.Handled, saver.Last().State)
+	assert.Empty(t, r.errors)
+}
+
+func TestSaver_UnexpectedCall(t *testing.T) {
+	r := &recorder{}
+	saver := usecasetest.NewSaver(r)
+
+	saver.Save(&domain.Shipment{ID: 1})
+
+	assert.Len(t, r.errors, 1)
+}
+
+func TestSaver_ExpectCalled_TooManyCalls(t *testing.T) {
+	r := &recorder{}
+	saver := usecasetest.NewSaver(r).ExpectCalled(1)
+
+	saver.Save(&domain.Shipment{ID: 1})
+	saver.Save(&domain.Shipment{ID: 1})
+
+	assert.Len(t, r.errors, 1)
+	r.finish()
+	assert.Equal(t, "expected Save to be called 1 times but got 2", r.errors[1])
+}
+
+func TestSaver_ExpectSaved(t *testing.T) {
+	r := &recorder{}
+	saver := usecasetest.NewSaver(r).ExpectSaved(7, 1)
+
+	saver.Save(&domain.Shipment{ID: 7})
+	assert.Empty(t, r.errors)
+
+	saver.Save(&domain.Shipment{ID: 8})
+	r.finish()
+
+	if assert.Len(t, r.errors, 1) {
+		assert.Contains(t, r.errors[0], "unexpected call to Save")
+	}
+}
+
+func TestSaver_ExpectSaved_NotCalled(t *testing.T) {
+	r := &recorder{}
+	usecasetest.NewSaver(r).ExpectSaved(7, 1)
+
+	r.finish()
+
+	assert.Equal(t, []string{"expected Save(7) to be called 1 times but got 0"}, r.errors)
+}
+
+func TestSaver_AcceptsAny(t *testing.T) {
+	r := &recorder{}
+	saver := usecasetest.NewSaver(r).AcceptsAny()
+
+	saver.Save(&domain.Shipment{ID: 1})
+	saver.Save(&domain.Shipment{ID: 2})
+
+	assert.Len(t, saver.Saved(), 2)
+	assert.Empty(t, r.errors)
+}
+
+func TestSequence(t *testing.T) {
+	r := &recorder{}
+	sequence := usecasetest.NewSequence(r, 4, 9)
+
+	assert.Equal(t, domain.ShipmentID(4), sequence.Next())
+	assert.Equal(t, domain.ShipmentID(9), sequence.Next())
+	assert.Empty(t, r.errors)
+	assert.Equal(t, domain.ShipmentID(0), sequence.Next())
+	assert.Equal(t, []string{"unexpected call 3 to Next, only 2 ids were expected"}, r.errors)
+	assert.Equal(t, 3, sequence.Calls())
+}
+
+func TestPublisher(t *testing.T) {
+	r := &recorder{}
+	publisher := usecasetest.NewPublisher(r).ExpectCalled(2)
+
+	publisher.Publish(domain.Shipment{ID: 1})
+	r.finish()
+
+	assert.Len(t, publisher.Published(), 1)
+	assert.Equal(t, []string{"expected Publish to be called 2 times but got 1"}, r.errors)
+}
+
+func TestPublisher_UnexpectedCall(t *testing.T) {
+	r := &recorder{}
+	publisher := usecasetest.NewPublisher(r)
+
+	publisher.Publish(domain.Shipment{ID: 1})
+
+	assert.Len(t, r.errors, 1)
+}
+
+func TestLister(t *testing.T) {
+	r := &recorder{}
+	lister := usecasetest.NewLister(r).Returns([]domain.Shipment{{ID: 1}}, nil)
+
+	shipments, err := lister.List()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.Shipment{{ID: 1}}, shipments)
+	assert.Equal(t, 1, lister.Calls())
+	assert.Empty(t, r.errors)
+}
+
+func TestLister_UnexpectedCall(t *testing.T) {
+	r := &recorder{}
+	lister := usecasetest.NewLister(r)
+
+	lister.List()
+
+	assert.Equal(t, []string{"unexpected call to List()"}, r.errors)
+}
+
+func TestCarriers(t *testing.T) {
+	r := &recorder{}
+	route := domain.Route{Origin: "valid origin", Destination: "valid destination"}
+	c := fake.New("FK")
+	carriers := usecasetest.NewCarriers(r).Returns(route, "fake", c, nil)
+
+	name, found, err := carriers.ForRoute(route)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "fake", name)
+	assert.Equal(t, c, found)
+	assert.Equal(t, []domain.Route{route}, carriers.Calls())
+	assert.Empty(t, r.errors)
+}
+
+func TestCarriers_UnexpectedCall(t *testing.T) {
+	r := &recorder{}
+	carriers := usecasetest.NewCarriers(r)
+
+	_, found, err := carriers.ForRoute(domain.Route{Origin: "a", Destination: "b"})
+
+	assert.Nil(t, found)
+	assert.Equal(t, carrier.NoCarrierForRoute, err)
+	assert.Len(t, r.errors, 1)
+}
+
+func TestDangerousGoods(t *testing.T) {
+	r := &recorder{}
+	hazmat := usecasetest.NewDangerousGoods(r).Returns(errors.New("Check error"))
+
+	err := hazmat.Check(domain.Shipment{ID: 1})
+
+	assert.EqualError(t, err, "Check error")
+	assert.Len(t, hazmat.Checked(), 1)
+	assert.Empty(t, r.errors)
+}
+
+func TestDangerousGoods_UnexpectedCall(t *testing.T) {
+	r := &recorder{}
+	hazmat := usecasetest.NewDangerousGoods(r)
+
+	hazmat.Check(domain.Shipment{ID: 1})
+
+	assert.Len(t, r.errors, 1)
+}
+
+func TestRefunds(t *testing.T) {
+	r := &recorder{}
+	refunds := usecasetest.NewRefunds(r).Fails(errors.New("Refund error")).ExpectCalled(1)
+	instruction := domain.RefundInstruction{ShipmentID: 1, Reason: domain.CustomerRequest}
+
+	err := refunds.Refund(instruction)
+	r.finish()
+
+	assert.EqualError(t, err, "Refund error")
+	assert.Equal(t, []domain.RefundInstruction{instruction}, refunds.Requested())
+	assert.Empty(t, r.errors)
+}
+
+func TestRefunds_UnexpectedCall(t *testing.T) {
+	r := &recorder{}
+	refunds := usecasetest.NewRefunds(r)
+
+	refunds.Refund(domain.RefundInstruction{ShipmentID: 1})
+
+	assert.Len(t, r.errors, 1)
+}
@@ -0,0 +1,269 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+)
+
+const SignatureHeader = "X-Webhook-Signature"
+
+var InvalidTenant = errors.New("Invalid Tenant")
+var InvalidURL = errors.New("Invalid URL")
+var InvalidSecret = errors.New("Invalid Secret")
+var InvalidEvents = errors.New("Invalid Events")
+var Dropped = errors.New("Event dropped before delivery")
+
+// Subscription only receives the events of shipments owned by Tenant.
+type Subscription struct {
+	ID     string
+	Tenant domain.TenantID
+	URL    string
+	Secret string
+	Events []domain.ShipmentState
+}
+
+func (s Subscription) Validate() error {
+	if s.Tenant == "" {
+		return InvalidTenant
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return InvalidURL
+	}
+	if s.Secret == "" {
+		return InvalidSecret
+	}
+	if len(s.Events) == 0 {
+		return InvalidEvents
+	}
+
+	return nil
+}
+
+func (s Subscription) Accepts(tenant domain.TenantID, state domain.ShipmentState) bool {
+	if s.Tenant != tenant {
+		return false
+	}
+	for _, e := range s.Events {
+		if e == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Payload struct {
+	Tenant      domain.TenantID      `json:"tenant"`
+	Event       domain.ShipmentState `json:"event"`
+	ShipmentID  domain.ShipmentID    `json:"shipment_id"`
+	Origin      string               `json:"origin"`
+	Destination string               `json:"destination"`
+	OccurredAt  time.Time            `json:"occurred_at"`
+}
+
+type DeadLetter struct {
+	Subscription Subscription
+	Payload      Payload
+	Attempts     int
+	Err          error
+}
+
+// maxDeadLetters bounds the dead letters kept in memory; past it the oldest
+// are discarded.
+const maxDeadLetters = 1000
+
+// maxDrain bounds how much of a response body is read so the connection can
+// be reused.
+const maxDrain = 1 << 20
+
+type Dispatcher struct {
+	client         *http.Client
+	maxAttempts    int
+	backoff        time.Duration
+	sleep          func(context.Context, time.Duration) error
+	now            func() time.Time
+	maxDeadLetters int
+	mu             sync.Mutex
+	subscriptions  []Subscription
+	deadLetters    []DeadLetter
+}
+
+func NewDispatcher(client *http.Client, maxAttempts int, backoff time.Duration) *Dispatcher {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return &Dispatcher{
+		client:         client,
+		maxAttempts:    maxAttempts,
+		backoff:        backoff,
+		sleep:          sleep,
+		now:            time.Now,
+		maxDeadLetters: maxDeadLetters,
+	}
+}
+
+func (d *Dispatcher) Subscribe(s Subscription) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscriptions = append(d.subscriptions, s)
+
+	return nil
+}
+
+func (d *Dispatcher) Unsubscribe(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	kept := d.subscriptions[:0]
+	for _, s := range d.subscriptions {
+		if s.ID != id {
+			kept = append(kept, s)
+		}
+	}
+	d.subscriptions = kept
+}
+
+// Notify posts the shipment to every subscription of its tenant listening to
+// its current state. Deliveries that still fail after the last retry, or
+// when ctx is done, are dead-lettered.
+func (d *Dispatcher) Notify(ctx context.Context, s domain.Shipment) {
+	p := d.payload(s)
+	for _, sub := range d.matching(s.Tenant, s.State) {
+		attempts, err := d.deliver(ctx, sub, p)
+		if err != nil {
+			d.deadLetter(DeadLetter{sub, p, attempts, err})
+		}
+	}
+}
+
+// Drop dead-letters the shipment for every subscription that would have
+// been notified, without trying to deliver it.
+func (d *Dispatcher) Drop(s domain.Shipment) {
+	p := d.payload(s)
+	for _, sub := range d.matching(s.Tenant, s.State) {
+		d.deadLetter(DeadLetter{sub, p, 0, Dropped})
+	}
+}
+
+func (d *Dispatcher) payload(s domain.Shipment) Payload {
+	return Payload{
+		Tenant:      s.Tenant,
+		Event:       s.State,
+		ShipmentID:  s.ID,
+		Origin:      s.Origin,
+		Destination: s.Destination,
+		OccurredAt:  d.now().UTC(),
+	}
+}
+
+func (d *Dispatcher) deadLetter(l DeadLetter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.deadLetters) == d.maxDeadLetters {
+		d.deadLetters = append(d.deadLetters[:0], d.deadLetters[1:]...)
+	}
+	d.deadLetters = append(d.deadLetters, l)
+}
+
+// DeadLetters returns the latest dead letters, oldest first.
+func (d *Dispatcher) DeadLetters() []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]DeadLetter(nil), d.deadLetters...)
+}
+
+func (d *Dispatcher) matching(tenant domain.TenantID, state domain.ShipmentState) []Subscription {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var subs []Subscription
+	for _, s := range d.subscriptions {
+		if s.Accepts(tenant, state) {
+			subs = append(subs, s)
+		}
+	}
+
+	return subs
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, sub Subscription, p Payload) (int, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return 0, err
+	}
+
+	delay := d.backoff
+	for attempt := 1; ; attempt++ {
+		err = d.post(ctx, sub, body)
+		if err == nil || attempt == d.maxAttempts {
+			return attempt, err
+		}
+		if err := d.sleep(ctx, delay); err != nil {
+			return attempt, err
+		}
+		delay *= 2
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, sub Subscription, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, maxDrain))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// sleep waits for delay unless ctx is done first.
+func sleep(ctx context.Context, delay time.Duration) error {
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher_Deliver_ExponentialBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	d := NewDispatcher(server.Client(), 4, 100*time.Millisecond)
+	d.sleep = func(_ context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+		return nil
+	}
+
+	attempts, err := d.deliver(context.Background(), Subscription{URL: server.URL, Secret: "secret"}, Payload{Event: domain.Shipped})
+
+	assert.NotNilf(t, err, "expected error but found none")
+	assert.Equal(t, 4, attempts)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}, delays)
+}
+
+func TestDispatcher_Deliver_StopsBackoffOnContext(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(server.Client(), 4, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	attempts, err := d.deliver(ctx, Subscription{URL: server.URL, Secret: "secret"}, Payload{Event: domain.Shipped})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDispatcher_Deliver_ReusesConnection(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("ok", 384<<10)))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	d := NewDispatcher(server.Client(), 1, 0)
+	for i := 0; i < 3; i++ {
+		_, err := d.deliver(context.Background(), Subscription{URL: server.URL, Secret: "secret"}, Payload{Event: domain.Shipped})
+		assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&conns), "expected the response bodies to be drained so the connection is reused")
+}
+
+func TestDispatcher_DeadLetters_KeepsLatest(t *testing.T) {
+	d := NewDispatcher(http.DefaultClient, 1, 0)
+	d.maxDeadLetters = 2
+	d.Subscribe(Subscription{ID: "merchant", Tenant: "acme", URL: "https://hooks.acme.example", Secret: "secret", Events: []domain.ShipmentState{domain.Shipped}})
+
+	for id := domain.ShipmentID(1); id <= 3; id++ {
+		d.Drop(domain.Shipment{Tenant: "acme", ID: id, State: domain.Shipped})
+	}
+
+	dead := d.DeadLetters()
+	if assert.Len(t, dead, 2) {
+		assert.Equal(t, domain.ShipmentID(2), dead[0].Payload.ShipmentID)
+		assert.Equal(t, domain.ShipmentID(3), dead[1].Payload.ShipmentID)
+	}
+}
@@ -0,0 +1,224 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/facucachomeli/workshop-go-testing/domain"
+	"github.com/facucachomeli/workshop-go-testing/domain/shipmenttest"
+	"github.com/facucachomeli/workshop-go-testing/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscription_Validate_Error(t *testing.T) {
+	cases := []struct {
+		name          string
+		subscription  webhook.Subscription
+		expectedError error
+	}{
+		{
+			name:          "Invalid Tenant",
+			subscription:  webhook.Subscription{URL: "https://merchant.test/hooks", Secret: "secret", Events: []domain.ShipmentState{domain.Shipped}},
+			expectedError: webhook.InvalidTenant,
+		},
+		{
+			name:          "Invalid URL",
+			subscription:  webhook.Subscription{Tenant: "acme", URL: "not a url", Secret: "secret", Events: []domain.ShipmentState{domain.Shipped}},
+			expectedError: webhook.InvalidURL,
+		},
+		{
+			name:          "Invalid Secret",
+			subscription:  webhook.Subscription{Tenant: "acme", URL: "https://merchant.test/hooks", Events: []domain.ShipmentState{domain.Shipped}},
+			expectedError: webhook.InvalidSecret,
+		},
+		{
+			name:          "Invalid Events",
+			subscription:  webhook.Subscription{Tenant: "acme", URL: "https://merchant.test/hooks", Secret: "secret"},
+			expectedError: webhook.InvalidEvents,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.subscription.Validate()
+			assert.Equal(t, c.expectedError, err)
+		})
+	}
+}
+
+func TestDispatcher_Notify_OK(t *testing.T) {
+	var received webhook.Payload
+	var signature string
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		signature = r.Header.Get(webhook.SignatureHeader)
+		if signature != webhook.Sign("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher(server.Client(), 3, 0)
+	err := d.Subscribe(webhook.Subscription{
+		ID:     "merchant",
+		Tenant: "acme",
+		URL:    server.URL,
+		Secret: "secret",
+		Events: []domain.ShipmentState{domain.Shipped, domain.Delivered},
+	})
+	assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+
+	d.Notify(context.Background(), shipmenttest.AShipment().ForTenant("acme").InState(domain.Delivered).Build())
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, domain.TenantID("acme"), received.Tenant)
+	assert.Equal(t, domain.Delivered, received.Event)
+	assert.Equal(t, domain.ShipmentID(1), received.ShipmentID)
+	assert.Equal(t, "valid origin", received.Origin)
+	assert.Equal(t, "valid destination", received.Destination)
+	assert.Empty(t, d.DeadLetters())
+}
+
+func TestDispatcher_Notify_FilteredOut(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher(server.Client(), 3, 0)
+	d.Subscribe(webhook.Subscription{Tenant: "acme", URL: server.URL, Secret: "secret", Events: []domain.ShipmentState{domain.Delivered}})
+
+	d.Notify(context.Background(), domain.Shipment{Tenant: "acme", ID: 1, State: domain.Created})
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestDispatcher_Notify_OtherTenant(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher(server.Client(), 3, 0)
+	d.Subscribe(webhook.Subscription{Tenant: "acme", URL: server.URL, Secret: "secret", Events: []domain.ShipmentState{domain.Shipped}})
+
+	d.Notify(context.Background(), domain.Shipment{Tenant: "globex", ID: 1, State: domain.Shipped})
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestDispatcher_Notify_RetriesUntilSuccess(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher(server.Client(), 3, 0)
+	d.Subscribe(webhook.Subscription{Tenant: "acme", URL: server.URL, Secret: "secret", Events: []domain.ShipmentState{domain.Shipped}})
+
+	d.Notify(context.Background(), domain.Shipment{Tenant: "acme", ID: 1, State: domain.Shipped})
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Empty(t, d.DeadLetters())
+}
+
+func TestDispatcher_Notify_DeadLetter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher(server.Client(), 4, 0)
+	d.Subscribe(webhook.Subscription{ID: "merchant", Tenant: "acme", URL: server.URL, Secret: "secret", Events: []domain.ShipmentState{domain.Shipped}})
+
+	d.Notify(context.Background(), domain.Shipment{Tenant: "acme", ID: 7, State: domain.Shipped})
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+	dead := d.DeadLetters()
+	if assert.Len(t, dead, 1) {
+		assert.Equal(t, "merchant", dead[0].Subscription.ID)
+		assert.Equal(t, domain.ShipmentID(7), dead[0].Payload.ShipmentID)
+		assert.Equal(t, 4, dead[0].Attempts)
+		assert.NotNil(t, dead[0].Err)
+	}
+}
+
+func TestDispatcher_Unsubscribe(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher(server.Client(), 1, 0)
+	d.Subscribe(webhook.Subscription{ID: "merchant", Tenant: "acme", URL: server.URL, Secret: "secret", Events: []domain.ShipmentState{domain.Shipped}})
+	d.Unsubscribe("merchant")
+
+	d.Notify(context.Background(), domain.Shipment{Tenant: "acme", ID: 1, State: domain.Shipped})
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func FuzzPayload_RoundTrip(f *testing.F) {
+	f.Add([]byte(`{"event":"Delivered","shipment_id":1,"origin":"valid origin","destination":"valid destination","occurred_at":"2019-10-01T10:00:00Z"}`))
+	f.Add([]byte(`{"event":"Shipped","shipment_id":7}`))
+	f.Add([]byte(`{}`))
+	f.Add([]byte(`not json`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var p webhook.Payload
+		if err := json.Unmarshal(data, &p); err != nil {
+			return
+		}
+
+		encoded, err := json.Marshal(p)
+		if err != nil {
+			return
+		}
+		var decoded webhook.Payload
+		err = json.Unmarshal(encoded, &decoded)
+		assert.Nilf(t, err, "expected error to be nil but got '%s'", err)
+		reencoded, _ := json.Marshal(decoded)
+		assert.Equal(t, string(encoded), string(reencoded))
+		assert.Equal(t, webhook.Sign("secret", encoded), webhook.Sign("secret", reencoded))
+	})
+}
+
+func TestDispatcher_Drop(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	d := webhook.NewDispatcher(server.Client(), 3, 0)
+	d.Subscribe(webhook.Subscription{ID: "merchant", Tenant: "acme", URL: server.URL, Secret: "secret", Events: []domain.ShipmentState{domain.Shipped}})
+	d.Subscribe(webhook.Subscription{ID: "other", Tenant: "globex", URL: server.URL, Secret: "secret", Events: []domain.ShipmentState{domain.Shipped}})
+
+	d.Drop(domain.Shipment{Tenant: "acme", ID: 7, State: domain.Shipped})
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	dead := d.DeadLetters()
+	if assert.Len(t, dead, 1) {
+		assert.Equal(t, "merchant", dead[0].Subscription.ID)
+		assert.Equal(t, domain.ShipmentID(7), dead[0].Payload.ShipmentID)
+		assert.Equal(t, 0, dead[0].Attempts)
+		assert.Equal(t, webhook.Dropped, dead[0].Err)
+	}
+}